@@ -0,0 +1,208 @@
+// Package cache memoizes expensive calculations. Results are kept in a
+// bounded LRU cache, and concurrent requests for a result still being
+// computed share one computation, each following it from the start as values
+// become available.
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+// Stats counts how requests to a Cache were served.
+type Stats struct {
+	Hits      uint64 // served from the cache
+	Misses    uint64 // started a computation
+	Coalesced uint64 // joined a computation already running
+	Evictions uint64
+	Entries   int
+	Capacity  int
+}
+
+// ComputeFunc computes the values for a key, passing each to emit as soon as
+// it is known. It must stop when ctx is done.
+type ComputeFunc func(ctx context.Context, emit func(v interface{})) error
+
+// Cache is a bounded LRU cache of value sequences, safe for concurrent use.
+type Cache struct {
+	capacity int
+
+	mu      sync.Mutex
+	lru     *list.List // of *item, most recently used first
+	items   map[string]*list.Element
+	flights map[string]*flight
+	stats   Stats
+}
+
+type item struct {
+	key    string
+	values []interface{}
+}
+
+// New returns an empty Cache keeping up to capacity results.
+func New(capacity int) *Cache {
+	return &Cache{
+		capacity: capacity,
+		lru:      list.New(),
+		items:    make(map[string]*list.Element),
+		flights:  make(map[string]*flight),
+	}
+}
+
+// Stats returns a snapshot of the cache statistics.
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.stats
+	s.Entries = c.lru.Len()
+	s.Capacity = c.capacity
+	return s
+}
+
+// Stream passes the values for key to emit, in order. They come from the
+// cache if present, else from the computation of key already running, else
+// from a new one started with compute. Only successful computations are
+// cached.
+//
+// A computation runs detached from the ctx of the request that started it,
+// and is canceled once every request following it has returned. Stream
+// returns the first error from emit, ctx or the computation.
+func (c *Cache) Stream(ctx context.Context, key string, compute ComputeFunc, emit func(v interface{}) error) error {
+	c.mu.Lock()
+	if e, ok := c.items[key]; ok {
+		c.lru.MoveToFront(e)
+		c.stats.Hits++
+		values := e.Value.(*item).values
+		c.mu.Unlock()
+		for _, v := range values {
+			if err := emit(v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	f, ok := c.flights[key]
+	if ok {
+		c.stats.Coalesced++
+	} else {
+		c.stats.Misses++
+		f = c.start(key, compute)
+	}
+	f.subscribers++
+	c.mu.Unlock()
+
+	defer c.leave(key, f)
+	return f.follow(ctx, emit)
+}
+
+// start runs compute for key in the background. c.mu must be held.
+func (c *Cache) start(key string, compute ComputeFunc) *flight {
+	ctx, cancel := context.WithCancel(context.Background())
+	f := &flight{cancel: cancel, changed: make(chan struct{})}
+	c.flights[key] = f
+	go func() {
+		defer cancel()
+		err := compute(ctx, f.add)
+		f.finish(err)
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.flights[key] == f {
+			delete(c.flights, key)
+		}
+		if err == nil {
+			c.add(key, f.values)
+		}
+	}()
+	return f
+}
+
+// leave unsubscribes from f, canceling it if nobody follows it any more.
+func (c *Cache) leave(key string, f *flight) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	f.subscribers--
+	if f.subscribers == 0 {
+		f.cancel()
+		if c.flights[key] == f {
+			delete(c.flights, key)
+		}
+	}
+}
+
+// add caches values under key, evicting the least recently used result if
+// the cache is full. c.mu must be held.
+func (c *Cache) add(key string, values []interface{}) {
+	if c.capacity <= 0 {
+		return
+	}
+	if e, ok := c.items[key]; ok {
+		c.lru.MoveToFront(e)
+		e.Value.(*item).values = values
+		return
+	}
+	c.items[key] = c.lru.PushFront(&item{key, values})
+	if c.lru.Len() > c.capacity {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.items, oldest.Value.(*item).key)
+		c.stats.Evictions++
+	}
+}
+
+// flight is a computation in progress. Its fields other than subscribers
+// are guarded by mu; subscribers is guarded by the Cache mutex.
+type flight struct {
+	cancel      context.CancelFunc
+	subscribers int
+
+	mu      sync.Mutex
+	values  []interface{}
+	done    bool
+	err     error
+	changed chan struct{} // closed and replaced whenever the fields change
+}
+
+func (f *flight) add(v interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.values = append(f.values, v)
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+func (f *flight) finish(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.done, f.err = true, err
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+// follow passes every value of f to emit, waiting for more until f is done.
+func (f *flight) follow(ctx context.Context, emit func(v interface{}) error) error {
+	for next := 0; ; {
+		f.mu.Lock()
+		values := f.values[next:]
+		done, err, changed := f.done, f.err, f.changed
+		f.mu.Unlock()
+
+		for _, v := range values {
+			if err := emit(v); err != nil {
+				return err
+			}
+		}
+		next += len(values)
+		if done {
+			return err
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
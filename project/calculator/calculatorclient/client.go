@@ -0,0 +1,412 @@
+// Package calculatorclient is a client library for the calculator server.
+// It wraps the generated stubs with plain Go signatures:
+//
+//	c, err := calculatorclient.Dial("localhost:50051", calculatorclient.WithCAFile("ssl/ca.crt"))
+//	if err != nil {
+//		return err
+//	}
+//	defer c.Close()
+//	sum, err := c.Sum(ctx, 3, 10)
+//
+// Connections use TLS with the system roots unless configured otherwise,
+// unary calls time out after 30 seconds unless their context has a
+// deadline, and calls failing with UNAVAILABLE are retried, except those
+// with side effects such as CreateSession, Memory and StartOperation.
+// Errors from the server are gRPC status errors, to be inspected with
+// status.Code and status.Convert. RPCs without a wrapper are reachable
+// through the stubs returned by Calculator, Matrix, Units and Operations.
+package calculatorclient
+
+import (
+	"context"
+	"io"
+	"math/big"
+	"strconv"
+
+	"github.com/grpc-project02/project/calculator/calculatorpb"
+	"github.com/grpc-project02/project/internal/clientconn"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Option configures Dial.
+type Option = clientconn.Option
+
+// Options for Dial, shared with the greet client library.
+var (
+	// WithInsecure connects without TLS.
+	WithInsecure = clientconn.WithInsecure
+	// WithCAFile verifies the server against the CA certificate in a PEM file.
+	WithCAFile = clientconn.WithCAFile
+	// WithTLSConfig connects with the given TLS configuration.
+	WithTLSConfig = clientconn.WithTLSConfig
+	// WithTimeout sets the timeout of unary calls; zero disables it.
+	WithTimeout = clientconn.WithTimeout
+	// WithRetries sets how many attempts are made and the first backoff.
+	WithRetries = clientconn.WithRetries
+	// WithDialOptions passes extra options to grpc.Dial.
+	WithDialOptions = clientconn.WithDialOptions
+)
+
+var services = []string{
+	"calculator.CalculatorService",
+	"calculator.MatrixService",
+	"calculator.UnitService",
+	"calculator.OperationsService",
+}
+
+// once lists the methods with side effects, which are not retried.
+var once = []string{
+	"calculator.CalculatorService/CreateSession",
+	"calculator.CalculatorService/CloseSession",
+	"calculator.CalculatorService/Memory",
+	"calculator.OperationsService/StartOperation",
+}
+
+// Client calls the services of a calculator server. It is safe for
+// concurrent use.
+type Client struct {
+	conn       *grpc.ClientConn // nil if not owned
+	calculator calculatorpb.CalculatorServiceClient
+	matrix     calculatorpb.MatrixServiceClient
+	units      calculatorpb.UnitServiceClient
+	operations calculatorpb.OperationsServiceClient
+}
+
+// Dial connects to the calculator server at target, such as
+// "localhost:50051".
+func Dial(target string, opts ...Option) (*Client, error) {
+	conn, err := clientconn.Dial(target, services, once, opts...)
+	if err != nil {
+		return nil, err
+	}
+	c := New(conn)
+	c.conn = conn
+	return c, nil
+}
+
+// New returns a client using an existing connection, which Close leaves
+// open.
+func New(conn grpc.ClientConnInterface) *Client {
+	return &Client{
+		calculator: calculatorpb.NewCalculatorServiceClient(conn),
+		matrix:     calculatorpb.NewMatrixServiceClient(conn),
+		units:      calculatorpb.NewUnitServiceClient(conn),
+		operations: calculatorpb.NewOperationsServiceClient(conn),
+	}
+}
+
+// Close closes the connection opened by Dial.
+func (c *Client) Close() error {
+	if c.conn == nil {
+		return nil
+	}
+	return c.conn.Close()
+}
+
+// Calculator returns the stub of CalculatorService.
+func (c *Client) Calculator() calculatorpb.CalculatorServiceClient { return c.calculator }
+
+// Matrix returns the stub of MatrixService.
+func (c *Client) Matrix() calculatorpb.MatrixServiceClient { return c.matrix }
+
+// Units returns the stub of UnitService.
+func (c *Client) Units() calculatorpb.UnitServiceClient { return c.units }
+
+// Operations returns the stub of OperationsService.
+func (c *Client) Operations() calculatorpb.OperationsServiceClient { return c.operations }
+
+// Sum returns a+b. It fails with OUT_OF_RANGE on overflow.
+func (c *Client) Sum(ctx context.Context, a, b int64) (int64, error) {
+	res, err := c.calculator.Sum(ctx, &calculatorpb.SumRequest{FirstNumber: a, SecondNumber: b})
+	if err != nil {
+		return 0, err
+	}
+	return res.GetResult(), nil
+}
+
+// SquareRoot returns the square root of n, which must not be negative.
+func (c *Client) SquareRoot(ctx context.Context, n int64) (float64, error) {
+	res, err := c.calculator.SquareRoot(ctx, &calculatorpb.SquareRootRequest{Number: n})
+	if err != nil {
+		return 0, err
+	}
+	return res.GetNumberRoot(), nil
+}
+
+// Evaluate returns the value of an expression such as "2 * (x + 1) ^ 2"
+// with the given variables, which may be nil.
+func (c *Client) Evaluate(ctx context.Context, expression string, variables map[string]float64) (float64, error) {
+	res, err := c.calculator.Evaluate(ctx, &calculatorpb.EvaluateRequest{Expression: expression, Variables: variables})
+	if err != nil {
+		return 0, err
+	}
+	return res.GetResult(), nil
+}
+
+// Differentiate returns the derivative of expression with respect to
+// variable, or x if it is empty.
+func (c *Client) Differentiate(ctx context.Context, expression, variable string) (string, error) {
+	res, err := c.calculator.Differentiate(ctx, &calculatorpb.DifferentiateRequest{Expression: expression, Variable: variable})
+	if err != nil {
+		return "", err
+	}
+	return res.GetDerivative(), nil
+}
+
+// Convert returns value, in the unit from, expressed in the unit to.
+func (c *Client) Convert(ctx context.Context, value float64, from, to string) (float64, error) {
+	res, err := c.units.Convert(ctx, &calculatorpb.ConvertRequest{
+		Quantity: &calculatorpb.Quantity{Value: value, Unit: from},
+		To:       to,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return res.GetQuantity().GetValue(), nil
+}
+
+// Average returns the average of numbers, streamed to the server.
+func (c *Client) Average(ctx context.Context, numbers []int64) (float64, error) {
+	stream, err := c.calculator.ComputeAverage(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, n := range numbers {
+		if err := stream.Send(&calculatorpb.ComputeAverageRequest{Number: n}); err != nil {
+			if err == io.EOF {
+				// The server ended the call; its status comes with the
+				// response.
+				break
+			}
+			return 0, err
+		}
+	}
+	res, err := stream.CloseAndRecv()
+	if err != nil {
+		return 0, err
+	}
+	return res.GetAverage(), nil
+}
+
+// Maximums streams numbers to FindMaximum and returns the running maximum
+// each time it changed.
+func (c *Client) Maximums(ctx context.Context, numbers []int64) ([]int64, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := c.calculator.FindMaximum(ctx)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		for _, n := range numbers {
+			if err := stream.Send(&calculatorpb.FindMaximumRequest{Number: n}); err != nil {
+				return
+			}
+		}
+		stream.CloseSend()
+	}()
+	var maximums []int64
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			return maximums, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		maximums = append(maximums, res.GetMax())
+	}
+}
+
+// Int64Iterator iterates over the numbers a call streams back:
+//
+//	it := c.Factorize(ctx, 120)
+//	defer it.Close()
+//	for it.Next() {
+//		fmt.Println(it.Value())
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+type Int64Iterator struct {
+	recv   func() (int64, error)
+	cancel context.CancelFunc
+	value  int64
+	err    error
+}
+
+// Next advances to the next number, returning false at the end of the
+// stream or on error.
+func (it *Int64Iterator) Next() bool {
+	if it.recv == nil {
+		return false
+	}
+	v, err := it.recv()
+	if err != nil {
+		if err != io.EOF {
+			it.err = err
+		}
+		it.Close()
+		return false
+	}
+	it.value = v
+	return true
+}
+
+// Value returns the current number.
+func (it *Int64Iterator) Value() int64 { return it.value }
+
+// Err returns the error that ended the stream, if any.
+func (it *Int64Iterator) Err() error { return it.err }
+
+// Close cancels the call if it is still running.
+func (it *Int64Iterator) Close() {
+	it.recv = nil
+	it.cancel()
+}
+
+// Factorize streams the prime factors of n in ascending order, repeated by
+// multiplicity. Small factors arrive as they are found, larger ones once n
+// is fully factored.
+func (c *Client) Factorize(ctx context.Context, n int64) *Int64Iterator {
+	ctx, cancel := context.WithCancel(ctx)
+	it := &Int64Iterator{cancel: cancel}
+	stream, err := c.calculator.PrimeNumberDecomposition(ctx, &calculatorpb.PrimeNumberDecompositionRequest{Number: n})
+	if err != nil {
+		it.err = err
+		cancel()
+		return it
+	}
+	it.recv = func() (int64, error) {
+		res, err := stream.Recv()
+		if err != nil || res.GetPrime() != 0 {
+			return res.GetPrime(), err
+		}
+		// Only big_prime is set. The factors of an int64 fit in one, so
+		// it still parses.
+		p, err := strconv.ParseInt(res.GetBigPrime(), 10, 64)
+		if err != nil {
+			return 0, status.Errorf(codes.Internal, "the server sent %q as a prime factor", res.GetBigPrime())
+		}
+		return p, nil
+	}
+	return it
+}
+
+// Primes streams the primes between from and to inclusive.
+func (c *Client) Primes(ctx context.Context, from, to int64) *Int64Iterator {
+	ctx, cancel := context.WithCancel(ctx)
+	it := &Int64Iterator{cancel: cancel}
+	stream, err := c.calculator.StreamPrimes(ctx, &calculatorpb.StreamPrimesRequest{From: from, To: to})
+	if err != nil {
+		it.err = err
+		cancel()
+		return it
+	}
+	it.recv = func() (int64, error) {
+		res, err := stream.Recv()
+		return res.GetPrime(), err
+	}
+	return it
+}
+
+// Sequence names a sequence GenerateSequence can stream.
+type Sequence = calculatorpb.GenerateSequenceRequest_Sequence
+
+// The sequences Terms can stream without further parameters.
+const (
+	Fibonacci = calculatorpb.GenerateSequenceRequest_FIBONACCI
+	Factorial = calculatorpb.GenerateSequenceRequest_FACTORIAL
+	Catalan   = calculatorpb.GenerateSequenceRequest_CATALAN
+)
+
+// TermIterator iterates over the terms of a sequence, like Int64Iterator.
+type TermIterator struct {
+	stream calculatorpb.CalculatorService_GenerateSequenceClient
+	cancel context.CancelFunc
+	index  uint64
+	term   *big.Int
+	err    error
+}
+
+// Next advances to the next term, returning false at the end of the stream
+// or on error.
+func (it *TermIterator) Next() bool {
+	if it.stream == nil {
+		return false
+	}
+	res, err := it.stream.Recv()
+	if err != nil {
+		if err != io.EOF {
+			it.err = err
+		}
+		it.Close()
+		return false
+	}
+	term, ok := new(big.Int).SetString(res.GetTerm(), 10)
+	if !ok {
+		it.err = status.Errorf(codes.Internal, "the server sent %q as term %d", res.GetTerm(), res.GetIndex())
+		it.Close()
+		return false
+	}
+	it.index, it.term = res.GetIndex(), term
+	return true
+}
+
+// Index returns the index of the current term.
+func (it *TermIterator) Index() uint64 { return it.index }
+
+// Term returns the current term.
+func (it *TermIterator) Term() *big.Int { return it.term }
+
+// Err returns the error that ended the stream, if any.
+func (it *TermIterator) Err() error { return it.err }
+
+// Close cancels the call if it is still running.
+func (it *TermIterator) Close() {
+	it.stream = nil
+	it.cancel()
+}
+
+// Terms streams count terms of seq from index start, or terms until they
+// grow too large if count is zero. To resume a stream, pass the index after
+// the last term received.
+func (c *Client) Terms(ctx context.Context, seq Sequence, start, count uint64) *TermIterator {
+	return c.generate(ctx, &calculatorpb.GenerateSequenceRequest{Sequence: seq, Start: start, Count: count})
+}
+
+// LinearRecurrence streams terms of the sequence with the given initial
+// terms that continues as
+//
+//	a(n) = coefficients[0]*a(n-1) + ... + coefficients[k-1]*a(n-k)
+//
+// like Terms.
+func (c *Client) LinearRecurrence(ctx context.Context, coefficients, initial []*big.Int, start, count uint64) *TermIterator {
+	req := &calculatorpb.GenerateSequenceRequest{
+		Sequence: calculatorpb.GenerateSequenceRequest_LINEAR_RECURRENCE,
+		Start:    start,
+		Count:    count,
+	}
+	for _, n := range coefficients {
+		req.Coefficients = append(req.Coefficients, n.String())
+	}
+	for _, n := range initial {
+		req.InitialTerms = append(req.InitialTerms, n.String())
+	}
+	return c.generate(ctx, req)
+}
+
+func (c *Client) generate(ctx context.Context, req *calculatorpb.GenerateSequenceRequest) *TermIterator {
+	ctx, cancel := context.WithCancel(ctx)
+	it := &TermIterator{cancel: cancel}
+	stream, err := c.calculator.GenerateSequence(ctx, req)
+	if err != nil {
+		it.err = err
+		cancel()
+		return it
+	}
+	it.stream = stream
+	return it
+}
@@ -0,0 +1,152 @@
+package calculatorclient
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/grpc-project02/project/calculator/calculatorpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// fakeServer answers the calls the tests make.
+type fakeServer struct {
+	calculatorpb.UnimplementedCalculatorServiceServer
+	// unavailable is how many calls fail with UNAVAILABLE before they
+	// succeed.
+	unavailable int32
+	calls       int32
+	// canceled is closed when a factorization of 0 sees its call canceled.
+	canceled chan struct{}
+}
+
+func (s *fakeServer) fail() error {
+	if atomic.AddInt32(&s.calls, 1) <= s.unavailable {
+		return status.Error(codes.Unavailable, "try again")
+	}
+	return nil
+}
+
+func (s *fakeServer) Sum(ctx context.Context, req *calculatorpb.SumRequest) (*calculatorpb.SumResponse, error) {
+	if err := s.fail(); err != nil {
+		return nil, err
+	}
+	return &calculatorpb.SumResponse{Result: req.GetFirstNumber() + req.GetSecondNumber()}, nil
+}
+
+func (s *fakeServer) CreateSession(ctx context.Context, req *calculatorpb.CreateSessionRequest) (*calculatorpb.CreateSessionResponse, error) {
+	if err := s.fail(); err != nil {
+		return nil, err
+	}
+	return &calculatorpb.CreateSessionResponse{}, nil
+}
+
+// PrimeNumberDecomposition sends the factors of 12 * 1000000007, the large
+// one as big_prime only, and blocks for 0 until the call ends.
+func (s *fakeServer) PrimeNumberDecomposition(req *calculatorpb.PrimeNumberDecompositionRequest, stream calculatorpb.CalculatorService_PrimeNumberDecompositionServer) error {
+	if req.GetNumber() == 0 {
+		stream.Send(&calculatorpb.PrimeNumberDecompositionResponse{Prime: 2, BigPrime: "2"})
+		<-stream.Context().Done()
+		close(s.canceled)
+		return stream.Context().Err()
+	}
+	for _, p := range []int64{2, 2, 3} {
+		stream.Send(&calculatorpb.PrimeNumberDecompositionResponse{Prime: p, BigPrime: strconv.FormatInt(p, 10)})
+	}
+	return stream.Send(&calculatorpb.PrimeNumberDecompositionResponse{BigPrime: "1000000007"})
+}
+
+// dial serves s over an in-memory listener and returns a client of it.
+func dial(t *testing.T, s *fakeServer) *Client {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	calculatorpb.RegisterCalculatorServiceServer(srv, s)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	c, err := Dial("bufnet", WithInsecure(), WithRetries(3, time.Millisecond),
+		WithDialOptions(grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		})))
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestSum(t *testing.T) {
+	c := dial(t, &fakeServer{})
+	if sum, err := c.Sum(context.Background(), 3, 10); sum != 13 || err != nil {
+		t.Errorf("Sum(3, 10) = %v, %v, want 13", sum, err)
+	}
+}
+
+func TestRetries(t *testing.T) {
+	s := &fakeServer{unavailable: 2}
+	c := dial(t, s)
+	if sum, err := c.Sum(context.Background(), 1, 2); sum != 3 || err != nil {
+		t.Errorf("Sum after two UNAVAILABLE = %v, %v, want 3", sum, err)
+	}
+	if calls := atomic.LoadInt32(&s.calls); calls != 3 {
+		t.Errorf("Sum was tried %d times, want 3", calls)
+	}
+
+	// CreateSession has side effects, so it is tried once.
+	s = &fakeServer{unavailable: 1}
+	c = dial(t, s)
+	if _, err := c.Calculator().CreateSession(context.Background(), &calculatorpb.CreateSessionRequest{}); status.Code(err) != codes.Unavailable {
+		t.Errorf("CreateSession = %v, want UNAVAILABLE", err)
+	}
+	if calls := atomic.LoadInt32(&s.calls); calls != 1 {
+		t.Errorf("CreateSession was tried %d times, want 1", calls)
+	}
+}
+
+func TestFactorize(t *testing.T) {
+	c := dial(t, &fakeServer{})
+	it := c.Factorize(context.Background(), 12*1000000007)
+	defer it.Close()
+	var factors []int64
+	for it.Next() {
+		factors = append(factors, it.Value())
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("Err() = %v", err)
+	}
+	want := []int64{2, 2, 3, 1000000007}
+	if len(factors) != len(want) {
+		t.Fatalf("factors = %v, want %v", factors, want)
+	}
+	for i := range want {
+		if factors[i] != want[i] {
+			t.Errorf("factors = %v, want %v", factors, want)
+			break
+		}
+	}
+}
+
+func TestIteratorClose(t *testing.T) {
+	s := &fakeServer{canceled: make(chan struct{})}
+	c := dial(t, s)
+	it := c.Factorize(context.Background(), 0)
+	if !it.Next() || it.Value() != 2 {
+		t.Fatalf("first factor = %v, %v, want 2", it.Value(), it.Err())
+	}
+	it.Close()
+	select {
+	case <-s.canceled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the server did not see the call canceled")
+	}
+	if it.Next() || it.Err() != nil {
+		t.Errorf("after Close: Next() true or Err() = %v", it.Err())
+	}
+}
@@ -0,0 +1,1641 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.27.1-devel
+// 	protoc        v3.15.8
+// source: calculator/calculatorpb/matrix.proto
+
+package calculatorpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type MatrixStreamHeader_Operation int32
+
+const (
+	MatrixStreamHeader_MULTIPLY    MatrixStreamHeader_Operation = 0
+	MatrixStreamHeader_TRANSPOSE   MatrixStreamHeader_Operation = 1
+	MatrixStreamHeader_DETERMINANT MatrixStreamHeader_Operation = 2
+	MatrixStreamHeader_INVERSE     MatrixStreamHeader_Operation = 3
+	MatrixStreamHeader_SOLVE       MatrixStreamHeader_Operation = 4
+)
+
+// Enum value maps for MatrixStreamHeader_Operation.
+var (
+	MatrixStreamHeader_Operation_name = map[int32]string{
+		0: "MULTIPLY",
+		1: "TRANSPOSE",
+		2: "DETERMINANT",
+		3: "INVERSE",
+		4: "SOLVE",
+	}
+	MatrixStreamHeader_Operation_value = map[string]int32{
+		"MULTIPLY":    0,
+		"TRANSPOSE":   1,
+		"DETERMINANT": 2,
+		"INVERSE":     3,
+		"SOLVE":       4,
+	}
+)
+
+func (x MatrixStreamHeader_Operation) Enum() *MatrixStreamHeader_Operation {
+	p := new(MatrixStreamHeader_Operation)
+	*p = x
+	return p
+}
+
+func (x MatrixStreamHeader_Operation) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (MatrixStreamHeader_Operation) Descriptor() protoreflect.EnumDescriptor {
+	return file_calculator_calculatorpb_matrix_proto_enumTypes[0].Descriptor()
+}
+
+func (MatrixStreamHeader_Operation) Type() protoreflect.EnumType {
+	return &file_calculator_calculatorpb_matrix_proto_enumTypes[0]
+}
+
+func (x MatrixStreamHeader_Operation) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use MatrixStreamHeader_Operation.Descriptor instead.
+func (MatrixStreamHeader_Operation) EnumDescriptor() ([]byte, []int) {
+	return file_calculator_calculatorpb_matrix_proto_rawDescGZIP(), []int{9, 0}
+}
+
+type OperandRow_Operand int32
+
+const (
+	OperandRow_A OperandRow_Operand = 0
+	OperandRow_B OperandRow_Operand = 1
+)
+
+// Enum value maps for OperandRow_Operand.
+var (
+	OperandRow_Operand_name = map[int32]string{
+		0: "A",
+		1: "B",
+	}
+	OperandRow_Operand_value = map[string]int32{
+		"A": 0,
+		"B": 1,
+	}
+)
+
+func (x OperandRow_Operand) Enum() *OperandRow_Operand {
+	p := new(OperandRow_Operand)
+	*p = x
+	return p
+}
+
+func (x OperandRow_Operand) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (OperandRow_Operand) Descriptor() protoreflect.EnumDescriptor {
+	return file_calculator_calculatorpb_matrix_proto_enumTypes[1].Descriptor()
+}
+
+func (OperandRow_Operand) Type() protoreflect.EnumType {
+	return &file_calculator_calculatorpb_matrix_proto_enumTypes[1]
+}
+
+func (x OperandRow_Operand) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use OperandRow_Operand.Descriptor instead.
+func (OperandRow_Operand) EnumDescriptor() ([]byte, []int) {
+	return file_calculator_calculatorpb_matrix_proto_rawDescGZIP(), []int{10, 0}
+}
+
+type Matrix struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Rows uint32 `protobuf:"varint,1,opt,name=rows,proto3" json:"rows,omitempty"`
+	Cols uint32 `protobuf:"varint,2,opt,name=cols,proto3" json:"cols,omitempty"`
+	// Row-major, rows * cols values.
+	Values []float64 `protobuf:"fixed64,3,rep,packed,name=values,proto3" json:"values,omitempty"`
+}
+
+func (x *Matrix) Reset() {
+	*x = Matrix{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_calculator_calculatorpb_matrix_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Matrix) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Matrix) ProtoMessage() {}
+
+func (x *Matrix) ProtoReflect() protoreflect.Message {
+	mi := &file_calculator_calculatorpb_matrix_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Matrix.ProtoReflect.Descriptor instead.
+func (*Matrix) Descriptor() ([]byte, []int) {
+	return file_calculator_calculatorpb_matrix_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Matrix) GetRows() uint32 {
+	if x != nil {
+		return x.Rows
+	}
+	return 0
+}
+
+func (x *Matrix) GetCols() uint32 {
+	if x != nil {
+		return x.Cols
+	}
+	return 0
+}
+
+func (x *Matrix) GetValues() []float64 {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+type MultiplyRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	A *Matrix `protobuf:"bytes,1,opt,name=a,proto3" json:"a,omitempty"`
+	B *Matrix `protobuf:"bytes,2,opt,name=b,proto3" json:"b,omitempty"`
+}
+
+func (x *MultiplyRequest) Reset() {
+	*x = MultiplyRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_calculator_calculatorpb_matrix_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MultiplyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MultiplyRequest) ProtoMessage() {}
+
+func (x *MultiplyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_calculator_calculatorpb_matrix_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MultiplyRequest.ProtoReflect.Descriptor instead.
+func (*MultiplyRequest) Descriptor() ([]byte, []int) {
+	return file_calculator_calculatorpb_matrix_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *MultiplyRequest) GetA() *Matrix {
+	if x != nil {
+		return x.A
+	}
+	return nil
+}
+
+func (x *MultiplyRequest) GetB() *Matrix {
+	if x != nil {
+		return x.B
+	}
+	return nil
+}
+
+type TransposeRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	A *Matrix `protobuf:"bytes,1,opt,name=a,proto3" json:"a,omitempty"`
+}
+
+func (x *TransposeRequest) Reset() {
+	*x = TransposeRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_calculator_calculatorpb_matrix_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TransposeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransposeRequest) ProtoMessage() {}
+
+func (x *TransposeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_calculator_calculatorpb_matrix_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransposeRequest.ProtoReflect.Descriptor instead.
+func (*TransposeRequest) Descriptor() ([]byte, []int) {
+	return file_calculator_calculatorpb_matrix_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *TransposeRequest) GetA() *Matrix {
+	if x != nil {
+		return x.A
+	}
+	return nil
+}
+
+type DeterminantRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	A *Matrix `protobuf:"bytes,1,opt,name=a,proto3" json:"a,omitempty"`
+}
+
+func (x *DeterminantRequest) Reset() {
+	*x = DeterminantRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_calculator_calculatorpb_matrix_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeterminantRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeterminantRequest) ProtoMessage() {}
+
+func (x *DeterminantRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_calculator_calculatorpb_matrix_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeterminantRequest.ProtoReflect.Descriptor instead.
+func (*DeterminantRequest) Descriptor() ([]byte, []int) {
+	return file_calculator_calculatorpb_matrix_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *DeterminantRequest) GetA() *Matrix {
+	if x != nil {
+		return x.A
+	}
+	return nil
+}
+
+type DeterminantResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Determinant float64 `protobuf:"fixed64,1,opt,name=determinant,proto3" json:"determinant,omitempty"`
+}
+
+func (x *DeterminantResponse) Reset() {
+	*x = DeterminantResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_calculator_calculatorpb_matrix_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeterminantResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeterminantResponse) ProtoMessage() {}
+
+func (x *DeterminantResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_calculator_calculatorpb_matrix_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeterminantResponse.ProtoReflect.Descriptor instead.
+func (*DeterminantResponse) Descriptor() ([]byte, []int) {
+	return file_calculator_calculatorpb_matrix_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *DeterminantResponse) GetDeterminant() float64 {
+	if x != nil {
+		return x.Determinant
+	}
+	return 0
+}
+
+type InverseRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	A *Matrix `protobuf:"bytes,1,opt,name=a,proto3" json:"a,omitempty"`
+}
+
+func (x *InverseRequest) Reset() {
+	*x = InverseRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_calculator_calculatorpb_matrix_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *InverseRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InverseRequest) ProtoMessage() {}
+
+func (x *InverseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_calculator_calculatorpb_matrix_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InverseRequest.ProtoReflect.Descriptor instead.
+func (*InverseRequest) Descriptor() ([]byte, []int) {
+	return file_calculator_calculatorpb_matrix_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *InverseRequest) GetA() *Matrix {
+	if x != nil {
+		return x.A
+	}
+	return nil
+}
+
+// Solves a·x = b for x. b may have several columns, one system per column.
+type SolveRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	A *Matrix `protobuf:"bytes,1,opt,name=a,proto3" json:"a,omitempty"`
+	B *Matrix `protobuf:"bytes,2,opt,name=b,proto3" json:"b,omitempty"`
+}
+
+func (x *SolveRequest) Reset() {
+	*x = SolveRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_calculator_calculatorpb_matrix_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SolveRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SolveRequest) ProtoMessage() {}
+
+func (x *SolveRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_calculator_calculatorpb_matrix_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SolveRequest.ProtoReflect.Descriptor instead.
+func (*SolveRequest) Descriptor() ([]byte, []int) {
+	return file_calculator_calculatorpb_matrix_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *SolveRequest) GetA() *Matrix {
+	if x != nil {
+		return x.A
+	}
+	return nil
+}
+
+func (x *SolveRequest) GetB() *Matrix {
+	if x != nil {
+		return x.B
+	}
+	return nil
+}
+
+type MatrixResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Matrix *Matrix `protobuf:"bytes,1,opt,name=matrix,proto3" json:"matrix,omitempty"`
+}
+
+func (x *MatrixResponse) Reset() {
+	*x = MatrixResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_calculator_calculatorpb_matrix_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MatrixResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MatrixResponse) ProtoMessage() {}
+
+func (x *MatrixResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_calculator_calculatorpb_matrix_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MatrixResponse.ProtoReflect.Descriptor instead.
+func (*MatrixResponse) Descriptor() ([]byte, []int) {
+	return file_calculator_calculatorpb_matrix_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *MatrixResponse) GetMatrix() *Matrix {
+	if x != nil {
+		return x.Matrix
+	}
+	return nil
+}
+
+type Shape struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Rows uint32 `protobuf:"varint,1,opt,name=rows,proto3" json:"rows,omitempty"`
+	Cols uint32 `protobuf:"varint,2,opt,name=cols,proto3" json:"cols,omitempty"`
+}
+
+func (x *Shape) Reset() {
+	*x = Shape{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_calculator_calculatorpb_matrix_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Shape) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Shape) ProtoMessage() {}
+
+func (x *Shape) ProtoReflect() protoreflect.Message {
+	mi := &file_calculator_calculatorpb_matrix_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Shape.ProtoReflect.Descriptor instead.
+func (*Shape) Descriptor() ([]byte, []int) {
+	return file_calculator_calculatorpb_matrix_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Shape) GetRows() uint32 {
+	if x != nil {
+		return x.Rows
+	}
+	return 0
+}
+
+func (x *Shape) GetCols() uint32 {
+	if x != nil {
+		return x.Cols
+	}
+	return 0
+}
+
+// Opens a streamed operation. b is only used by MULTIPLY and SOLVE.
+type MatrixStreamHeader struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Operation MatrixStreamHeader_Operation `protobuf:"varint,1,opt,name=operation,proto3,enum=calculator.MatrixStreamHeader_Operation" json:"operation,omitempty"`
+	A         *Shape                       `protobuf:"bytes,2,opt,name=a,proto3" json:"a,omitempty"`
+	B         *Shape                       `protobuf:"bytes,3,opt,name=b,proto3" json:"b,omitempty"`
+}
+
+func (x *MatrixStreamHeader) Reset() {
+	*x = MatrixStreamHeader{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_calculator_calculatorpb_matrix_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MatrixStreamHeader) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MatrixStreamHeader) ProtoMessage() {}
+
+func (x *MatrixStreamHeader) ProtoReflect() protoreflect.Message {
+	mi := &file_calculator_calculatorpb_matrix_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MatrixStreamHeader.ProtoReflect.Descriptor instead.
+func (*MatrixStreamHeader) Descriptor() ([]byte, []int) {
+	return file_calculator_calculatorpb_matrix_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *MatrixStreamHeader) GetOperation() MatrixStreamHeader_Operation {
+	if x != nil {
+		return x.Operation
+	}
+	return MatrixStreamHeader_MULTIPLY
+}
+
+func (x *MatrixStreamHeader) GetA() *Shape {
+	if x != nil {
+		return x.A
+	}
+	return nil
+}
+
+func (x *MatrixStreamHeader) GetB() *Shape {
+	if x != nil {
+		return x.B
+	}
+	return nil
+}
+
+type OperandRow struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Operand OperandRow_Operand `protobuf:"varint,1,opt,name=operand,proto3,enum=calculator.OperandRow_Operand" json:"operand,omitempty"`
+	Row     uint32             `protobuf:"varint,2,opt,name=row,proto3" json:"row,omitempty"`
+	Values  []float64          `protobuf:"fixed64,3,rep,packed,name=values,proto3" json:"values,omitempty"`
+}
+
+func (x *OperandRow) Reset() {
+	*x = OperandRow{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_calculator_calculatorpb_matrix_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *OperandRow) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OperandRow) ProtoMessage() {}
+
+func (x *OperandRow) ProtoReflect() protoreflect.Message {
+	mi := &file_calculator_calculatorpb_matrix_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OperandRow.ProtoReflect.Descriptor instead.
+func (*OperandRow) Descriptor() ([]byte, []int) {
+	return file_calculator_calculatorpb_matrix_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *OperandRow) GetOperand() OperandRow_Operand {
+	if x != nil {
+		return x.Operand
+	}
+	return OperandRow_A
+}
+
+func (x *OperandRow) GetRow() uint32 {
+	if x != nil {
+		return x.Row
+	}
+	return 0
+}
+
+func (x *OperandRow) GetValues() []float64 {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+type MatrixRow struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Row    uint32    `protobuf:"varint,1,opt,name=row,proto3" json:"row,omitempty"`
+	Values []float64 `protobuf:"fixed64,2,rep,packed,name=values,proto3" json:"values,omitempty"`
+}
+
+func (x *MatrixRow) Reset() {
+	*x = MatrixRow{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_calculator_calculatorpb_matrix_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MatrixRow) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MatrixRow) ProtoMessage() {}
+
+func (x *MatrixRow) ProtoReflect() protoreflect.Message {
+	mi := &file_calculator_calculatorpb_matrix_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MatrixRow.ProtoReflect.Descriptor instead.
+func (*MatrixRow) Descriptor() ([]byte, []int) {
+	return file_calculator_calculatorpb_matrix_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *MatrixRow) GetRow() uint32 {
+	if x != nil {
+		return x.Row
+	}
+	return 0
+}
+
+func (x *MatrixRow) GetValues() []float64 {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+type StreamMatrixRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Payload:
+	//	*StreamMatrixRequest_Header
+	//	*StreamMatrixRequest_Row
+	Payload isStreamMatrixRequest_Payload `protobuf_oneof:"payload"`
+}
+
+func (x *StreamMatrixRequest) Reset() {
+	*x = StreamMatrixRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_calculator_calculatorpb_matrix_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StreamMatrixRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamMatrixRequest) ProtoMessage() {}
+
+func (x *StreamMatrixRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_calculator_calculatorpb_matrix_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamMatrixRequest.ProtoReflect.Descriptor instead.
+func (*StreamMatrixRequest) Descriptor() ([]byte, []int) {
+	return file_calculator_calculatorpb_matrix_proto_rawDescGZIP(), []int{12}
+}
+
+func (m *StreamMatrixRequest) GetPayload() isStreamMatrixRequest_Payload {
+	if m != nil {
+		return m.Payload
+	}
+	return nil
+}
+
+func (x *StreamMatrixRequest) GetHeader() *MatrixStreamHeader {
+	if x, ok := x.GetPayload().(*StreamMatrixRequest_Header); ok {
+		return x.Header
+	}
+	return nil
+}
+
+func (x *StreamMatrixRequest) GetRow() *OperandRow {
+	if x, ok := x.GetPayload().(*StreamMatrixRequest_Row); ok {
+		return x.Row
+	}
+	return nil
+}
+
+type isStreamMatrixRequest_Payload interface {
+	isStreamMatrixRequest_Payload()
+}
+
+type StreamMatrixRequest_Header struct {
+	Header *MatrixStreamHeader `protobuf:"bytes,1,opt,name=header,proto3,oneof"`
+}
+
+type StreamMatrixRequest_Row struct {
+	Row *OperandRow `protobuf:"bytes,2,opt,name=row,proto3,oneof"`
+}
+
+func (*StreamMatrixRequest_Header) isStreamMatrixRequest_Payload() {}
+
+func (*StreamMatrixRequest_Row) isStreamMatrixRequest_Payload() {}
+
+type StreamMatrixResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Payload:
+	//	*StreamMatrixResponse_Shape
+	//	*StreamMatrixResponse_Row
+	//	*StreamMatrixResponse_Determinant
+	Payload isStreamMatrixResponse_Payload `protobuf_oneof:"payload"`
+}
+
+func (x *StreamMatrixResponse) Reset() {
+	*x = StreamMatrixResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_calculator_calculatorpb_matrix_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StreamMatrixResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamMatrixResponse) ProtoMessage() {}
+
+func (x *StreamMatrixResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_calculator_calculatorpb_matrix_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamMatrixResponse.ProtoReflect.Descriptor instead.
+func (*StreamMatrixResponse) Descriptor() ([]byte, []int) {
+	return file_calculator_calculatorpb_matrix_proto_rawDescGZIP(), []int{13}
+}
+
+func (m *StreamMatrixResponse) GetPayload() isStreamMatrixResponse_Payload {
+	if m != nil {
+		return m.Payload
+	}
+	return nil
+}
+
+func (x *StreamMatrixResponse) GetShape() *Shape {
+	if x, ok := x.GetPayload().(*StreamMatrixResponse_Shape); ok {
+		return x.Shape
+	}
+	return nil
+}
+
+func (x *StreamMatrixResponse) GetRow() *MatrixRow {
+	if x, ok := x.GetPayload().(*StreamMatrixResponse_Row); ok {
+		return x.Row
+	}
+	return nil
+}
+
+func (x *StreamMatrixResponse) GetDeterminant() float64 {
+	if x, ok := x.GetPayload().(*StreamMatrixResponse_Determinant); ok {
+		return x.Determinant
+	}
+	return 0
+}
+
+type isStreamMatrixResponse_Payload interface {
+	isStreamMatrixResponse_Payload()
+}
+
+type StreamMatrixResponse_Shape struct {
+	// Sent first for operations with a matrix result, followed by its rows.
+	Shape *Shape `protobuf:"bytes,1,opt,name=shape,proto3,oneof"`
+}
+
+type StreamMatrixResponse_Row struct {
+	Row *MatrixRow `protobuf:"bytes,2,opt,name=row,proto3,oneof"`
+}
+
+type StreamMatrixResponse_Determinant struct {
+	Determinant float64 `protobuf:"fixed64,3,opt,name=determinant,proto3,oneof"`
+}
+
+func (*StreamMatrixResponse_Shape) isStreamMatrixResponse_Payload() {}
+
+func (*StreamMatrixResponse_Row) isStreamMatrixResponse_Payload() {}
+
+func (*StreamMatrixResponse_Determinant) isStreamMatrixResponse_Payload() {}
+
+var File_calculator_calculatorpb_matrix_proto protoreflect.FileDescriptor
+
+var file_calculator_calculatorpb_matrix_proto_rawDesc = []byte{
+	0x0a, 0x24, 0x63, 0x61, 0x6c, 0x63, 0x75, 0x6c, 0x61, 0x74, 0x6f, 0x72, 0x2f, 0x63, 0x61, 0x6c,
+	0x63, 0x75, 0x6c, 0x61, 0x74, 0x6f, 0x72, 0x70, 0x62, 0x2f, 0x6d, 0x61, 0x74, 0x72, 0x69, 0x78,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0a, 0x63, 0x61, 0x6c, 0x63, 0x75, 0x6c, 0x61, 0x74,
+	0x6f, 0x72, 0x22, 0x48, 0x0a, 0x06, 0x4d, 0x61, 0x74, 0x72, 0x69, 0x78, 0x12, 0x12, 0x0a, 0x04,
+	0x72, 0x6f, 0x77, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x72, 0x6f, 0x77, 0x73,
+	0x12, 0x12, 0x0a, 0x04, 0x63, 0x6f, 0x6c, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04,
+	0x63, 0x6f, 0x6c, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x18, 0x03,
+	0x20, 0x03, 0x28, 0x01, 0x52, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x22, 0x55, 0x0a, 0x0f,
+	0x4d, 0x75, 0x6c, 0x74, 0x69, 0x70, 0x6c, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x20, 0x0a, 0x01, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x63, 0x61, 0x6c,
+	0x63, 0x75, 0x6c, 0x61, 0x74, 0x6f, 0x72, 0x2e, 0x4d, 0x61, 0x74, 0x72, 0x69, 0x78, 0x52, 0x01,
+	0x61, 0x12, 0x20, 0x0a, 0x01, 0x62, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x63,
+	0x61, 0x6c, 0x63, 0x75, 0x6c, 0x61, 0x74, 0x6f, 0x72, 0x2e, 0x4d, 0x61, 0x74, 0x72, 0x69, 0x78,
+	0x52, 0x01, 0x62, 0x22, 0x34, 0x0a, 0x10, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x70, 0x6f, 0x73, 0x65,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x20, 0x0a, 0x01, 0x61, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x12, 0x2e, 0x63, 0x61, 0x6c, 0x63, 0x75, 0x6c, 0x61, 0x74, 0x6f, 0x72, 0x2e,
+	0x4d, 0x61, 0x74, 0x72, 0x69, 0x78, 0x52, 0x01, 0x61, 0x22, 0x36, 0x0a, 0x12, 0x44, 0x65, 0x74,
+	0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x20, 0x0a, 0x01, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x63, 0x61, 0x6c,
+	0x63, 0x75, 0x6c, 0x61, 0x74, 0x6f, 0x72, 0x2e, 0x4d, 0x61, 0x74, 0x72, 0x69, 0x78, 0x52, 0x01,
+	0x61, 0x22, 0x37, 0x0a, 0x13, 0x44, 0x65, 0x74, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6e, 0x74,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x64, 0x65, 0x74, 0x65,
+	0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0b, 0x64,
+	0x65, 0x74, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6e, 0x74, 0x22, 0x32, 0x0a, 0x0e, 0x49, 0x6e,
+	0x76, 0x65, 0x72, 0x73, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x20, 0x0a, 0x01,
+	0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x63, 0x61, 0x6c, 0x63, 0x75, 0x6c,
+	0x61, 0x74, 0x6f, 0x72, 0x2e, 0x4d, 0x61, 0x74, 0x72, 0x69, 0x78, 0x52, 0x01, 0x61, 0x22, 0x52,
+	0x0a, 0x0c, 0x53, 0x6f, 0x6c, 0x76, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x20,
+	0x0a, 0x01, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x63, 0x61, 0x6c, 0x63,
+	0x75, 0x6c, 0x61, 0x74, 0x6f, 0x72, 0x2e, 0x4d, 0x61, 0x74, 0x72, 0x69, 0x78, 0x52, 0x01, 0x61,
+	0x12, 0x20, 0x0a, 0x01, 0x62, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x63, 0x61,
+	0x6c, 0x63, 0x75, 0x6c, 0x61, 0x74, 0x6f, 0x72, 0x2e, 0x4d, 0x61, 0x74, 0x72, 0x69, 0x78, 0x52,
+	0x01, 0x62, 0x22, 0x3c, 0x0a, 0x0e, 0x4d, 0x61, 0x74, 0x72, 0x69, 0x78, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2a, 0x0a, 0x06, 0x6d, 0x61, 0x74, 0x72, 0x69, 0x78, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x63, 0x61, 0x6c, 0x63, 0x75, 0x6c, 0x61, 0x74, 0x6f,
+	0x72, 0x2e, 0x4d, 0x61, 0x74, 0x72, 0x69, 0x78, 0x52, 0x06, 0x6d, 0x61, 0x74, 0x72, 0x69, 0x78,
+	0x22, 0x2f, 0x0a, 0x05, 0x53, 0x68, 0x61, 0x70, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x6f, 0x77,
+	0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x72, 0x6f, 0x77, 0x73, 0x12, 0x12, 0x0a,
+	0x04, 0x63, 0x6f, 0x6c, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x63, 0x6f, 0x6c,
+	0x73, 0x22, 0xf1, 0x01, 0x0a, 0x12, 0x4d, 0x61, 0x74, 0x72, 0x69, 0x78, 0x53, 0x74, 0x72, 0x65,
+	0x61, 0x6d, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x46, 0x0a, 0x09, 0x6f, 0x70, 0x65, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x28, 0x2e, 0x63, 0x61,
+	0x6c, 0x63, 0x75, 0x6c, 0x61, 0x74, 0x6f, 0x72, 0x2e, 0x4d, 0x61, 0x74, 0x72, 0x69, 0x78, 0x53,
+	0x74, 0x72, 0x65, 0x61, 0x6d, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x2e, 0x4f, 0x70, 0x65, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x09, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x12, 0x1f, 0x0a, 0x01, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x63, 0x61,
+	0x6c, 0x63, 0x75, 0x6c, 0x61, 0x74, 0x6f, 0x72, 0x2e, 0x53, 0x68, 0x61, 0x70, 0x65, 0x52, 0x01,
+	0x61, 0x12, 0x1f, 0x0a, 0x01, 0x62, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x63,
+	0x61, 0x6c, 0x63, 0x75, 0x6c, 0x61, 0x74, 0x6f, 0x72, 0x2e, 0x53, 0x68, 0x61, 0x70, 0x65, 0x52,
+	0x01, 0x62, 0x22, 0x51, 0x0a, 0x09, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12,
+	0x0c, 0x0a, 0x08, 0x4d, 0x55, 0x4c, 0x54, 0x49, 0x50, 0x4c, 0x59, 0x10, 0x00, 0x12, 0x0d, 0x0a,
+	0x09, 0x54, 0x52, 0x41, 0x4e, 0x53, 0x50, 0x4f, 0x53, 0x45, 0x10, 0x01, 0x12, 0x0f, 0x0a, 0x0b,
+	0x44, 0x45, 0x54, 0x45, 0x52, 0x4d, 0x49, 0x4e, 0x41, 0x4e, 0x54, 0x10, 0x02, 0x12, 0x0b, 0x0a,
+	0x07, 0x49, 0x4e, 0x56, 0x45, 0x52, 0x53, 0x45, 0x10, 0x03, 0x12, 0x09, 0x0a, 0x05, 0x53, 0x4f,
+	0x4c, 0x56, 0x45, 0x10, 0x04, 0x22, 0x89, 0x01, 0x0a, 0x0a, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x6e,
+	0x64, 0x52, 0x6f, 0x77, 0x12, 0x38, 0x0a, 0x07, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x6e, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1e, 0x2e, 0x63, 0x61, 0x6c, 0x63, 0x75, 0x6c, 0x61, 0x74,
+	0x6f, 0x72, 0x2e, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x6e, 0x64, 0x52, 0x6f, 0x77, 0x2e, 0x4f, 0x70,
+	0x65, 0x72, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x6e, 0x64, 0x12, 0x10,
+	0x0a, 0x03, 0x72, 0x6f, 0x77, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x03, 0x72, 0x6f, 0x77,
+	0x12, 0x16, 0x0a, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x01,
+	0x52, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x22, 0x17, 0x0a, 0x07, 0x4f, 0x70, 0x65, 0x72,
+	0x61, 0x6e, 0x64, 0x12, 0x05, 0x0a, 0x01, 0x41, 0x10, 0x00, 0x12, 0x05, 0x0a, 0x01, 0x42, 0x10,
+	0x01, 0x22, 0x35, 0x0a, 0x09, 0x4d, 0x61, 0x74, 0x72, 0x69, 0x78, 0x52, 0x6f, 0x77, 0x12, 0x10,
+	0x0a, 0x03, 0x72, 0x6f, 0x77, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x03, 0x72, 0x6f, 0x77,
+	0x12, 0x16, 0x0a, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x01,
+	0x52, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x22, 0x86, 0x01, 0x0a, 0x13, 0x53, 0x74, 0x72,
+	0x65, 0x61, 0x6d, 0x4d, 0x61, 0x74, 0x72, 0x69, 0x78, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x38, 0x0a, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1e, 0x2e, 0x63, 0x61, 0x6c, 0x63, 0x75, 0x6c, 0x61, 0x74, 0x6f, 0x72, 0x2e, 0x4d, 0x61,
+	0x74, 0x72, 0x69, 0x78, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72,
+	0x48, 0x00, 0x52, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x2a, 0x0a, 0x03, 0x72, 0x6f,
+	0x77, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x63, 0x61, 0x6c, 0x63, 0x75, 0x6c,
+	0x61, 0x74, 0x6f, 0x72, 0x2e, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x6e, 0x64, 0x52, 0x6f, 0x77, 0x48,
+	0x00, 0x52, 0x03, 0x72, 0x6f, 0x77, 0x42, 0x09, 0x0a, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61,
+	0x64, 0x22, 0x9b, 0x01, 0x0a, 0x14, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x4d, 0x61, 0x74, 0x72,
+	0x69, 0x78, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x29, 0x0a, 0x05, 0x73, 0x68,
+	0x61, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x63, 0x61, 0x6c, 0x63,
+	0x75, 0x6c, 0x61, 0x74, 0x6f, 0x72, 0x2e, 0x53, 0x68, 0x61, 0x70, 0x65, 0x48, 0x00, 0x52, 0x05,
+	0x73, 0x68, 0x61, 0x70, 0x65, 0x12, 0x29, 0x0a, 0x03, 0x72, 0x6f, 0x77, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x15, 0x2e, 0x63, 0x61, 0x6c, 0x63, 0x75, 0x6c, 0x61, 0x74, 0x6f, 0x72, 0x2e,
+	0x4d, 0x61, 0x74, 0x72, 0x69, 0x78, 0x52, 0x6f, 0x77, 0x48, 0x00, 0x52, 0x03, 0x72, 0x6f, 0x77,
+	0x12, 0x22, 0x0a, 0x0b, 0x64, 0x65, 0x74, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6e, 0x74, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x01, 0x48, 0x00, 0x52, 0x0b, 0x64, 0x65, 0x74, 0x65, 0x72, 0x6d, 0x69,
+	0x6e, 0x61, 0x6e, 0x74, 0x42, 0x09, 0x0a, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x32,
+	0xc7, 0x03, 0x0a, 0x0d, 0x4d, 0x61, 0x74, 0x72, 0x69, 0x78, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63,
+	0x65, 0x12, 0x43, 0x0a, 0x08, 0x4d, 0x75, 0x6c, 0x74, 0x69, 0x70, 0x6c, 0x79, 0x12, 0x1b, 0x2e,
+	0x63, 0x61, 0x6c, 0x63, 0x75, 0x6c, 0x61, 0x74, 0x6f, 0x72, 0x2e, 0x4d, 0x75, 0x6c, 0x74, 0x69,
+	0x70, 0x6c, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x63, 0x61, 0x6c,
+	0x63, 0x75, 0x6c, 0x61, 0x74, 0x6f, 0x72, 0x2e, 0x4d, 0x61, 0x74, 0x72, 0x69, 0x78, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x45, 0x0a, 0x09, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x70,
+	0x6f, 0x73, 0x65, 0x12, 0x1c, 0x2e, 0x63, 0x61, 0x6c, 0x63, 0x75, 0x6c, 0x61, 0x74, 0x6f, 0x72,
+	0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x70, 0x6f, 0x73, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x1a, 0x2e, 0x63, 0x61, 0x6c, 0x63, 0x75, 0x6c, 0x61, 0x74, 0x6f, 0x72, 0x2e, 0x4d,
+	0x61, 0x74, 0x72, 0x69, 0x78, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4e, 0x0a,
+	0x0b, 0x44, 0x65, 0x74, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6e, 0x74, 0x12, 0x1e, 0x2e, 0x63,
+	0x61, 0x6c, 0x63, 0x75, 0x6c, 0x61, 0x74, 0x6f, 0x72, 0x2e, 0x44, 0x65, 0x74, 0x65, 0x72, 0x6d,
+	0x69, 0x6e, 0x61, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x63,
+	0x61, 0x6c, 0x63, 0x75, 0x6c, 0x61, 0x74, 0x6f, 0x72, 0x2e, 0x44, 0x65, 0x74, 0x65, 0x72, 0x6d,
+	0x69, 0x6e, 0x61, 0x6e, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x41, 0x0a,
+	0x07, 0x49, 0x6e, 0x76, 0x65, 0x72, 0x73, 0x65, 0x12, 0x1a, 0x2e, 0x63, 0x61, 0x6c, 0x63, 0x75,
+	0x6c, 0x61, 0x74, 0x6f, 0x72, 0x2e, 0x49, 0x6e, 0x76, 0x65, 0x72, 0x73, 0x65, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x63, 0x61, 0x6c, 0x63, 0x75, 0x6c, 0x61, 0x74, 0x6f,
+	0x72, 0x2e, 0x4d, 0x61, 0x74, 0x72, 0x69, 0x78, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x3d, 0x0a, 0x05, 0x53, 0x6f, 0x6c, 0x76, 0x65, 0x12, 0x18, 0x2e, 0x63, 0x61, 0x6c, 0x63,
+	0x75, 0x6c, 0x61, 0x74, 0x6f, 0x72, 0x2e, 0x53, 0x6f, 0x6c, 0x76, 0x65, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x63, 0x61, 0x6c, 0x63, 0x75, 0x6c, 0x61, 0x74, 0x6f, 0x72,
+	0x2e, 0x4d, 0x61, 0x74, 0x72, 0x69, 0x78, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x58, 0x0a, 0x0f, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x12, 0x1f, 0x2e, 0x63, 0x61, 0x6c, 0x63, 0x75, 0x6c, 0x61, 0x74, 0x6f, 0x72, 0x2e,
+	0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x4d, 0x61, 0x74, 0x72, 0x69, 0x78, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x20, 0x2e, 0x63, 0x61, 0x6c, 0x63, 0x75, 0x6c, 0x61, 0x74, 0x6f, 0x72,
+	0x2e, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x4d, 0x61, 0x74, 0x72, 0x69, 0x78, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x28, 0x01, 0x30, 0x01, 0x42, 0x19, 0x5a, 0x17, 0x63, 0x61, 0x6c,
+	0x63, 0x75, 0x6c, 0x61, 0x74, 0x6f, 0x72, 0x2f, 0x63, 0x61, 0x6c, 0x63, 0x75, 0x6c, 0x61, 0x74,
+	0x6f, 0x72, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_calculator_calculatorpb_matrix_proto_rawDescOnce sync.Once
+	file_calculator_calculatorpb_matrix_proto_rawDescData = file_calculator_calculatorpb_matrix_proto_rawDesc
+)
+
+func file_calculator_calculatorpb_matrix_proto_rawDescGZIP() []byte {
+	file_calculator_calculatorpb_matrix_proto_rawDescOnce.Do(func() {
+		file_calculator_calculatorpb_matrix_proto_rawDescData = protoimpl.X.CompressGZIP(file_calculator_calculatorpb_matrix_proto_rawDescData)
+	})
+	return file_calculator_calculatorpb_matrix_proto_rawDescData
+}
+
+var file_calculator_calculatorpb_matrix_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_calculator_calculatorpb_matrix_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_calculator_calculatorpb_matrix_proto_goTypes = []interface{}{
+	(MatrixStreamHeader_Operation)(0), // 0: calculator.MatrixStreamHeader.Operation
+	(OperandRow_Operand)(0),           // 1: calculator.OperandRow.Operand
+	(*Matrix)(nil),                    // 2: calculator.Matrix
+	(*MultiplyRequest)(nil),           // 3: calculator.MultiplyRequest
+	(*TransposeRequest)(nil),          // 4: calculator.TransposeRequest
+	(*DeterminantRequest)(nil),        // 5: calculator.DeterminantRequest
+	(*DeterminantResponse)(nil),       // 6: calculator.DeterminantResponse
+	(*InverseRequest)(nil),            // 7: calculator.InverseRequest
+	(*SolveRequest)(nil),              // 8: calculator.SolveRequest
+	(*MatrixResponse)(nil),            // 9: calculator.MatrixResponse
+	(*Shape)(nil),                     // 10: calculator.Shape
+	(*MatrixStreamHeader)(nil),        // 11: calculator.MatrixStreamHeader
+	(*OperandRow)(nil),                // 12: calculator.OperandRow
+	(*MatrixRow)(nil),                 // 13: calculator.MatrixRow
+	(*StreamMatrixRequest)(nil),       // 14: calculator.StreamMatrixRequest
+	(*StreamMatrixResponse)(nil),      // 15: calculator.StreamMatrixResponse
+}
+var file_calculator_calculatorpb_matrix_proto_depIdxs = []int32{
+	2,  // 0: calculator.MultiplyRequest.a:type_name -> calculator.Matrix
+	2,  // 1: calculator.MultiplyRequest.b:type_name -> calculator.Matrix
+	2,  // 2: calculator.TransposeRequest.a:type_name -> calculator.Matrix
+	2,  // 3: calculator.DeterminantRequest.a:type_name -> calculator.Matrix
+	2,  // 4: calculator.InverseRequest.a:type_name -> calculator.Matrix
+	2,  // 5: calculator.SolveRequest.a:type_name -> calculator.Matrix
+	2,  // 6: calculator.SolveRequest.b:type_name -> calculator.Matrix
+	2,  // 7: calculator.MatrixResponse.matrix:type_name -> calculator.Matrix
+	0,  // 8: calculator.MatrixStreamHeader.operation:type_name -> calculator.MatrixStreamHeader.Operation
+	10, // 9: calculator.MatrixStreamHeader.a:type_name -> calculator.Shape
+	10, // 10: calculator.MatrixStreamHeader.b:type_name -> calculator.Shape
+	1,  // 11: calculator.OperandRow.operand:type_name -> calculator.OperandRow.Operand
+	11, // 12: calculator.StreamMatrixRequest.header:type_name -> calculator.MatrixStreamHeader
+	12, // 13: calculator.StreamMatrixRequest.row:type_name -> calculator.OperandRow
+	10, // 14: calculator.StreamMatrixResponse.shape:type_name -> calculator.Shape
+	13, // 15: calculator.StreamMatrixResponse.row:type_name -> calculator.MatrixRow
+	3,  // 16: calculator.MatrixService.Multiply:input_type -> calculator.MultiplyRequest
+	4,  // 17: calculator.MatrixService.Transpose:input_type -> calculator.TransposeRequest
+	5,  // 18: calculator.MatrixService.Determinant:input_type -> calculator.DeterminantRequest
+	7,  // 19: calculator.MatrixService.Inverse:input_type -> calculator.InverseRequest
+	8,  // 20: calculator.MatrixService.Solve:input_type -> calculator.SolveRequest
+	14, // 21: calculator.MatrixService.StreamOperation:input_type -> calculator.StreamMatrixRequest
+	9,  // 22: calculator.MatrixService.Multiply:output_type -> calculator.MatrixResponse
+	9,  // 23: calculator.MatrixService.Transpose:output_type -> calculator.MatrixResponse
+	6,  // 24: calculator.MatrixService.Determinant:output_type -> calculator.DeterminantResponse
+	9,  // 25: calculator.MatrixService.Inverse:output_type -> calculator.MatrixResponse
+	9,  // 26: calculator.MatrixService.Solve:output_type -> calculator.MatrixResponse
+	15, // 27: calculator.MatrixService.StreamOperation:output_type -> calculator.StreamMatrixResponse
+	22, // [22:28] is the sub-list for method output_type
+	16, // [16:22] is the sub-list for method input_type
+	16, // [16:16] is the sub-list for extension type_name
+	16, // [16:16] is the sub-list for extension extendee
+	0,  // [0:16] is the sub-list for field type_name
+}
+
+func init() { file_calculator_calculatorpb_matrix_proto_init() }
+func file_calculator_calculatorpb_matrix_proto_init() {
+	if File_calculator_calculatorpb_matrix_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_calculator_calculatorpb_matrix_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Matrix); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_calculator_calculatorpb_matrix_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MultiplyRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_calculator_calculatorpb_matrix_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TransposeRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_calculator_calculatorpb_matrix_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DeterminantRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_calculator_calculatorpb_matrix_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DeterminantResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_calculator_calculatorpb_matrix_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*InverseRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_calculator_calculatorpb_matrix_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SolveRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_calculator_calculatorpb_matrix_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MatrixResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_calculator_calculatorpb_matrix_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Shape); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_calculator_calculatorpb_matrix_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MatrixStreamHeader); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_calculator_calculatorpb_matrix_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*OperandRow); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_calculator_calculatorpb_matrix_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MatrixRow); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_calculator_calculatorpb_matrix_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StreamMatrixRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_calculator_calculatorpb_matrix_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StreamMatrixResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_calculator_calculatorpb_matrix_proto_msgTypes[12].OneofWrappers = []interface{}{
+		(*StreamMatrixRequest_Header)(nil),
+		(*StreamMatrixRequest_Row)(nil),
+	}
+	file_calculator_calculatorpb_matrix_proto_msgTypes[13].OneofWrappers = []interface{}{
+		(*StreamMatrixResponse_Shape)(nil),
+		(*StreamMatrixResponse_Row)(nil),
+		(*StreamMatrixResponse_Determinant)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_calculator_calculatorpb_matrix_proto_rawDesc,
+			NumEnums:      2,
+			NumMessages:   14,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_calculator_calculatorpb_matrix_proto_goTypes,
+		DependencyIndexes: file_calculator_calculatorpb_matrix_proto_depIdxs,
+		EnumInfos:         file_calculator_calculatorpb_matrix_proto_enumTypes,
+		MessageInfos:      file_calculator_calculatorpb_matrix_proto_msgTypes,
+	}.Build()
+	File_calculator_calculatorpb_matrix_proto = out.File
+	file_calculator_calculatorpb_matrix_proto_rawDesc = nil
+	file_calculator_calculatorpb_matrix_proto_goTypes = nil
+	file_calculator_calculatorpb_matrix_proto_depIdxs = nil
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// MatrixServiceClient is the client API for MatrixService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type MatrixServiceClient interface {
+	Multiply(ctx context.Context, in *MultiplyRequest, opts ...grpc.CallOption) (*MatrixResponse, error)
+	Transpose(ctx context.Context, in *TransposeRequest, opts ...grpc.CallOption) (*MatrixResponse, error)
+	Determinant(ctx context.Context, in *DeterminantRequest, opts ...grpc.CallOption) (*DeterminantResponse, error)
+	Inverse(ctx context.Context, in *InverseRequest, opts ...grpc.CallOption) (*MatrixResponse, error)
+	Solve(ctx context.Context, in *SolveRequest, opts ...grpc.CallOption) (*MatrixResponse, error)
+	// For matrices too large for one message: send a header, then every row of
+	// each operand in any order, then close the send side. The result comes
+	// back row by row.
+	StreamOperation(ctx context.Context, opts ...grpc.CallOption) (MatrixService_StreamOperationClient, error)
+}
+
+type matrixServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMatrixServiceClient(cc grpc.ClientConnInterface) MatrixServiceClient {
+	return &matrixServiceClient{cc}
+}
+
+func (c *matrixServiceClient) Multiply(ctx context.Context, in *MultiplyRequest, opts ...grpc.CallOption) (*MatrixResponse, error) {
+	out := new(MatrixResponse)
+	err := c.cc.Invoke(ctx, "/calculator.MatrixService/Multiply", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *matrixServiceClient) Transpose(ctx context.Context, in *TransposeRequest, opts ...grpc.CallOption) (*MatrixResponse, error) {
+	out := new(MatrixResponse)
+	err := c.cc.Invoke(ctx, "/calculator.MatrixService/Transpose", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *matrixServiceClient) Determinant(ctx context.Context, in *DeterminantRequest, opts ...grpc.CallOption) (*DeterminantResponse, error) {
+	out := new(DeterminantResponse)
+	err := c.cc.Invoke(ctx, "/calculator.MatrixService/Determinant", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *matrixServiceClient) Inverse(ctx context.Context, in *InverseRequest, opts ...grpc.CallOption) (*MatrixResponse, error) {
+	out := new(MatrixResponse)
+	err := c.cc.Invoke(ctx, "/calculator.MatrixService/Inverse", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *matrixServiceClient) Solve(ctx context.Context, in *SolveRequest, opts ...grpc.CallOption) (*MatrixResponse, error) {
+	out := new(MatrixResponse)
+	err := c.cc.Invoke(ctx, "/calculator.MatrixService/Solve", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *matrixServiceClient) StreamOperation(ctx context.Context, opts ...grpc.CallOption) (MatrixService_StreamOperationClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_MatrixService_serviceDesc.Streams[0], "/calculator.MatrixService/StreamOperation", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &matrixServiceStreamOperationClient{stream}
+	return x, nil
+}
+
+type MatrixService_StreamOperationClient interface {
+	Send(*StreamMatrixRequest) error
+	Recv() (*StreamMatrixResponse, error)
+	grpc.ClientStream
+}
+
+type matrixServiceStreamOperationClient struct {
+	grpc.ClientStream
+}
+
+func (x *matrixServiceStreamOperationClient) Send(m *StreamMatrixRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *matrixServiceStreamOperationClient) Recv() (*StreamMatrixResponse, error) {
+	m := new(StreamMatrixResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// MatrixServiceServer is the server API for MatrixService service.
+type MatrixServiceServer interface {
+	Multiply(context.Context, *MultiplyRequest) (*MatrixResponse, error)
+	Transpose(context.Context, *TransposeRequest) (*MatrixResponse, error)
+	Determinant(context.Context, *DeterminantRequest) (*DeterminantResponse, error)
+	Inverse(context.Context, *InverseRequest) (*MatrixResponse, error)
+	Solve(context.Context, *SolveRequest) (*MatrixResponse, error)
+	// For matrices too large for one message: send a header, then every row of
+	// each operand in any order, then close the send side. The result comes
+	// back row by row.
+	StreamOperation(MatrixService_StreamOperationServer) error
+}
+
+// UnimplementedMatrixServiceServer can be embedded to have forward compatible implementations.
+type UnimplementedMatrixServiceServer struct {
+}
+
+func (*UnimplementedMatrixServiceServer) Multiply(context.Context, *MultiplyRequest) (*MatrixResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Multiply not implemented")
+}
+func (*UnimplementedMatrixServiceServer) Transpose(context.Context, *TransposeRequest) (*MatrixResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Transpose not implemented")
+}
+func (*UnimplementedMatrixServiceServer) Determinant(context.Context, *DeterminantRequest) (*DeterminantResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Determinant not implemented")
+}
+func (*UnimplementedMatrixServiceServer) Inverse(context.Context, *InverseRequest) (*MatrixResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Inverse not implemented")
+}
+func (*UnimplementedMatrixServiceServer) Solve(context.Context, *SolveRequest) (*MatrixResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Solve not implemented")
+}
+func (*UnimplementedMatrixServiceServer) StreamOperation(MatrixService_StreamOperationServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamOperation not implemented")
+}
+
+func RegisterMatrixServiceServer(s *grpc.Server, srv MatrixServiceServer) {
+	s.RegisterService(&_MatrixService_serviceDesc, srv)
+}
+
+func _MatrixService_Multiply_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MultiplyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MatrixServiceServer).Multiply(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/calculator.MatrixService/Multiply",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MatrixServiceServer).Multiply(ctx, req.(*MultiplyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MatrixService_Transpose_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TransposeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MatrixServiceServer).Transpose(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/calculator.MatrixService/Transpose",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MatrixServiceServer).Transpose(ctx, req.(*TransposeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MatrixService_Determinant_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeterminantRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MatrixServiceServer).Determinant(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/calculator.MatrixService/Determinant",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MatrixServiceServer).Determinant(ctx, req.(*DeterminantRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MatrixService_Inverse_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InverseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MatrixServiceServer).Inverse(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/calculator.MatrixService/Inverse",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MatrixServiceServer).Inverse(ctx, req.(*InverseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MatrixService_Solve_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SolveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MatrixServiceServer).Solve(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/calculator.MatrixService/Solve",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MatrixServiceServer).Solve(ctx, req.(*SolveRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MatrixService_StreamOperation_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(MatrixServiceServer).StreamOperation(&matrixServiceStreamOperationServer{stream})
+}
+
+type MatrixService_StreamOperationServer interface {
+	Send(*StreamMatrixResponse) error
+	Recv() (*StreamMatrixRequest, error)
+	grpc.ServerStream
+}
+
+type matrixServiceStreamOperationServer struct {
+	grpc.ServerStream
+}
+
+func (x *matrixServiceStreamOperationServer) Send(m *StreamMatrixResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *matrixServiceStreamOperationServer) Recv() (*StreamMatrixRequest, error) {
+	m := new(StreamMatrixRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+var _MatrixService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "calculator.MatrixService",
+	HandlerType: (*MatrixServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Multiply",
+			Handler:    _MatrixService_Multiply_Handler,
+		},
+		{
+			MethodName: "Transpose",
+			Handler:    _MatrixService_Transpose_Handler,
+		},
+		{
+			MethodName: "Determinant",
+			Handler:    _MatrixService_Determinant_Handler,
+		},
+		{
+			MethodName: "Inverse",
+			Handler:    _MatrixService_Inverse_Handler,
+		},
+		{
+			MethodName: "Solve",
+			Handler:    _MatrixService_Solve_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamOperation",
+			Handler:       _MatrixService_StreamOperation_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "calculator/calculatorpb/matrix.proto",
+}
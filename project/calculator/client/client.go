@@ -6,6 +6,7 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"io"
 	"log"
@@ -23,9 +24,211 @@ func main() {
 	c := calculatorpb.NewCalculatorServiceClient(conn)
 	// log.Printf("Created client: %f", c)
 	// doServerStreaming(c)
+	// doStreamPrimes(c)
 	// doClientStreaming(c)
 	// doBiDiStreaming(c)
 	doErrorUnary(c)
+	// doEvaluate(c)
+	// doCalculate(c)
+	// doSession(c)
+	// doSolve(calculatorpb.NewMatrixServiceClient(conn))
+	// doSolveOde(c)
+	// doDifferentiate(c)
+	// doSample(c)
+	// doGenerateSequence(c)
+}
+
+func doGenerateSequence(c calculatorpb.CalculatorServiceClient) {
+	req := &calculatorpb.GenerateSequenceRequest{
+		Sequence: calculatorpb.GenerateSequenceRequest_FIBONACCI,
+		Start:    90,
+		Count:    10,
+	}
+	stream, err := c.GenerateSequence(context.Background(), req)
+	if err != nil {
+		log.Fatalf("Error while calling GenerateSequence RPC: %v", err)
+	}
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Fatalf("Error while reading stream: %v", err)
+		}
+		log.Printf("F(%v) = %v", res.GetIndex(), res.GetTerm())
+	}
+}
+
+func doSample(c calculatorpb.CalculatorServiceClient) {
+	seed := int64(1)
+	req := &calculatorpb.SampleRequest{
+		Distribution: &calculatorpb.Distribution{
+			Distribution: &calculatorpb.Distribution_Normal{Normal: &calculatorpb.NormalDistribution{Mean: 100, Stddev: 15}},
+		},
+		Count: 10,
+		Seed:  &seed,
+		Rate:  5,
+		Round: true,
+	}
+	stream, err := c.Sample(context.Background(), req)
+	if err != nil {
+		log.Fatalf("Error while calling Sample RPC: %v", err)
+	}
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Fatalf("Error while reading stream: %v", err)
+		}
+		log.Printf("Sampled %v", res.GetValue())
+	}
+}
+
+func doDifferentiate(c calculatorpb.CalculatorServiceClient) {
+	req := &calculatorpb.DifferentiateRequest{
+		Expression: "x^2 * sin(x)",
+	}
+	resp, err := c.Differentiate(context.Background(), req)
+	if err != nil {
+		respErr, ok := status.FromError(err)
+		if ok && respErr.Code() == codes.InvalidArgument {
+			log.Printf("Cannot differentiate: %v", respErr.Message())
+			return
+		}
+		log.Fatalf("Big Error calling Differentiate %v", err)
+	}
+	log.Printf("Response from Differentiate: %v", resp.GetDerivative())
+}
+
+func doSolveOde(c calculatorpb.CalculatorServiceClient) {
+	req := &calculatorpb.SolveOdeRequest{
+		Derivative:     "-k * y",
+		Y0:             1,
+		TEnd:           5,
+		SampleInterval: 0.5,
+		Variables:      map[string]float64{"k": 0.5},
+	}
+	stream, err := c.SolveOde(context.Background(), req)
+	if err != nil {
+		log.Fatalf("Error while calling SolveOde RPC: %v", err)
+	}
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if status.Code(err) == codes.FailedPrecondition {
+				log.Printf("Did not converge: %v", status.Convert(err).Message())
+				return
+			}
+			log.Fatalf("Error while reading stream: %v", err)
+		}
+		log.Printf("y(%v) = %v", res.GetT(), res.GetY())
+	}
+}
+
+func doCalculate(c calculatorpb.CalculatorServiceClient) {
+	stream, err := c.Calculate(context.Background())
+	if err != nil {
+		log.Fatalf("Error while creating stream: %v", err)
+	}
+
+	requests := []*calculatorpb.CalculateRequest{
+		{Id: "sum", Operation: &calculatorpb.CalculateRequest_Sum{Sum: &calculatorpb.SumRequest{FirstNumber: 3, SecondNumber: 10}}},
+		{Id: "sqrt", Operation: &calculatorpb.CalculateRequest_SquareRoot{SquareRoot: &calculatorpb.SquareRootRequest{Number: -1}}},
+		{Id: "factorize", Operation: &calculatorpb.CalculateRequest_Factorize{Factorize: &calculatorpb.PrimeNumberDecompositionRequest{Number: 120}}},
+		{Id: "evaluate", Operation: &calculatorpb.CalculateRequest_Evaluate{Evaluate: &calculatorpb.EvaluateRequest{Expression: "max(2, pi) ^ 2"}}},
+	}
+	go func() {
+		for _, req := range requests {
+			if err := stream.Send(req); err != nil {
+				log.Printf("Error while sending: %v", err)
+				return
+			}
+		}
+		stream.CloseSend()
+	}()
+
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Fatalf("Error while receiving: %v", err)
+		}
+		if e := res.GetError(); e != nil {
+			log.Printf("%v failed: %v (%v)", res.GetId(), e.GetMessage(), codes.Code(e.GetCode()))
+			continue
+		}
+		log.Printf("%v: %v", res.GetId(), res.GetResult())
+	}
+}
+
+func doSession(c calculatorpb.CalculatorServiceClient) {
+	res, err := c.CreateSession(context.Background(), &calculatorpb.CreateSessionRequest{})
+	if err != nil {
+		log.Fatalf("Error while creating session: %v", err)
+	}
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "calculator-session", res.GetSessionId())
+	defer c.CloseSession(ctx, &calculatorpb.CloseSessionRequest{})
+
+	if _, err := c.SetVariable(ctx, &calculatorpb.SetVariableRequest{Name: "rate", Value: 0.2}); err != nil {
+		log.Fatalf("Error while setting variable: %v", err)
+	}
+	for _, expression := range []string{"100 * rate", "ans + 100"} {
+		resp, err := c.Evaluate(ctx, &calculatorpb.EvaluateRequest{Expression: expression})
+		if err != nil {
+			log.Fatalf("Error while evaluating %v: %v", expression, err)
+		}
+		log.Printf("%v = %v", expression, resp.GetResult())
+	}
+	if _, err := c.Memory(ctx, &calculatorpb.MemoryRequest{Operation: calculatorpb.MemoryRequest_ADD}); err != nil {
+		log.Fatalf("Error while adding to memory: %v", err)
+	}
+	state, err := c.GetSession(ctx, &calculatorpb.GetSessionRequest{})
+	if err != nil {
+		log.Fatalf("Error while getting session: %v", err)
+	}
+	log.Printf("Session: %v", state)
+}
+
+func doSolve(c calculatorpb.MatrixServiceClient) {
+	req := &calculatorpb.SolveRequest{
+		A: &calculatorpb.Matrix{Rows: 2, Cols: 2, Values: []float64{2, 1, 1, 3}},
+		B: &calculatorpb.Matrix{Rows: 2, Cols: 1, Values: []float64{3, 5}},
+	}
+	resp, err := c.Solve(context.Background(), req)
+	if err != nil {
+		respErr, ok := status.FromError(err)
+		if ok && respErr.Code() == codes.InvalidArgument {
+			log.Printf("Invalid matrices: %v", respErr.Message())
+			return
+		}
+		log.Fatalf("Big Error calling Solve %v", err)
+	}
+	log.Printf("Response from Solve: %v", resp.GetMatrix().GetValues())
+}
+
+func doEvaluate(c calculatorpb.CalculatorServiceClient) {
+	req := &calculatorpb.EvaluateRequest{
+		Expression: "2 * (x + 1) ^ 2 - sqrt(y)",
+		Variables:  map[string]float64{"x": 3, "y": 16},
+	}
+	resp, err := c.Evaluate(context.Background(), req)
+	if err != nil {
+		respErr, ok := status.FromError(err)
+		if ok && respErr.Code() == codes.InvalidArgument {
+			log.Printf("Invalid expression: %v", respErr.Message())
+			return
+		}
+		log.Fatalf("Big Error calling Evaluate %v", err)
+	}
+	log.Printf("Response from Evaluate: %v", resp.GetResult())
 }
 
 func doErrorUnary(c calculatorpb.CalculatorServiceClient) {
@@ -165,6 +368,27 @@ func doServerStreaming(c calculatorpb.CalculatorServiceClient) {
 	}
 }
 
+func doStreamPrimes(c calculatorpb.CalculatorServiceClient) {
+	req := &calculatorpb.StreamPrimesRequest{
+		From: 1000000,
+		To:   1000100,
+	}
+	stream, err := c.StreamPrimes(context.Background(), req)
+	if err != nil {
+		return
+	}
+	for {
+		recv, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Fatalf("error while reading stream: %v", err)
+		}
+		log.Printf("Response from StreamPrimes: %v", recv.GetPrime())
+	}
+}
+
 func doUnary(c calculatorpb.CalculatorServiceClient) {
 	req := &calculatorpb.SumRequest{
 		FirstNumber:  3,
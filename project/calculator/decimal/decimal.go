@@ -0,0 +1,140 @@
+// Package decimal implements exact base-10 arithmetic on arbitrarily large
+// numbers, for callers that cannot accept binary floating point rounding.
+package decimal
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Decimal is the number unscaled × 10^-scale. The zero value is 0.
+type Decimal struct {
+	unscaled big.Int
+	scale    int32
+}
+
+var ten = big.NewInt(10)
+
+// ErrDivisionByZero is returned by Quo for a zero divisor.
+var ErrDivisionByZero = errors.New("division by zero")
+
+// Parse reads a number such as "-12", "0.5" or "1234.5678".
+func Parse(s string) (*Decimal, error) {
+	digits := s
+	if strings.HasPrefix(digits, "-") || strings.HasPrefix(digits, "+") {
+		digits = digits[1:]
+	}
+	intPart, fracPart := digits, ""
+	if i := strings.IndexByte(digits, '.'); i >= 0 {
+		intPart, fracPart = digits[:i], digits[i+1:]
+	}
+	if intPart == "" && fracPart == "" || !allDigits(intPart) || !allDigits(fracPart) {
+		return nil, fmt.Errorf("invalid decimal number %q", s)
+	}
+
+	d := &Decimal{scale: int32(len(fracPart))}
+	d.unscaled.SetString(intPart+fracPart, 10)
+	if s[0] == '-' {
+		d.unscaled.Neg(&d.unscaled)
+	}
+	return d, nil
+}
+
+func allDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// String formats d in plain notation, keeping its scale: 1.50 stays "1.50".
+func (d *Decimal) String() string {
+	s := new(big.Int).Abs(&d.unscaled).String()
+	if d.scale > 0 {
+		if pad := int(d.scale) + 1 - len(s); pad > 0 {
+			s = strings.Repeat("0", pad) + s
+		}
+		s = s[:len(s)-int(d.scale)] + "." + s[len(s)-int(d.scale):]
+	}
+	if d.unscaled.Sign() < 0 {
+		s = "-" + s
+	}
+	return s
+}
+
+// rescale returns the unscaled value of d expressed at a larger scale.
+func (d *Decimal) rescale(scale int32) *big.Int {
+	factor := new(big.Int).Exp(ten, big.NewInt(int64(scale-d.scale)), nil)
+	return factor.Mul(factor, &d.unscaled)
+}
+
+func maxScale(x, y *Decimal) int32 {
+	if x.scale > y.scale {
+		return x.scale
+	}
+	return y.scale
+}
+
+// Add returns x + y exactly.
+func Add(x, y *Decimal) *Decimal {
+	scale := maxScale(x, y)
+	z := &Decimal{scale: scale}
+	z.unscaled.Add(x.rescale(scale), y.rescale(scale))
+	return z
+}
+
+// Sub returns x - y exactly.
+func Sub(x, y *Decimal) *Decimal {
+	scale := maxScale(x, y)
+	z := &Decimal{scale: scale}
+	z.unscaled.Sub(x.rescale(scale), y.rescale(scale))
+	return z
+}
+
+// Mul returns x × y exactly.
+func Mul(x, y *Decimal) *Decimal {
+	z := &Decimal{scale: x.scale + y.scale}
+	z.unscaled.Mul(&x.unscaled, &y.unscaled)
+	return z
+}
+
+// Quo returns x ÷ y with scale digits after the decimal point, rounding half
+// to even as is usual for financial figures.
+func Quo(x, y *Decimal, scale int32) (*Decimal, error) {
+	if y.unscaled.Sign() == 0 {
+		return nil, ErrDivisionByZero
+	}
+
+	// x/y = (ux·10^-sx) / (uy·10^-sy); scale the numerator so the integer
+	// quotient has the requested number of fractional digits.
+	num := new(big.Int).Set(&x.unscaled)
+	den := new(big.Int).Set(&y.unscaled)
+	if shift := int64(scale) - int64(x.scale) + int64(y.scale); shift >= 0 {
+		num.Mul(num, new(big.Int).Exp(ten, big.NewInt(shift), nil))
+	} else {
+		den.Mul(den, new(big.Int).Exp(ten, big.NewInt(-shift), nil))
+	}
+
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	if r.Sign() != 0 {
+		// Compare 2|r| with |den| to decide which way to round.
+		twice := new(big.Int).Abs(r)
+		twice.Lsh(twice, 1)
+		cmp := twice.Cmp(new(big.Int).Abs(den))
+		if cmp > 0 || cmp == 0 && q.Bit(0) == 1 {
+			if num.Sign()*den.Sign() < 0 {
+				q.Sub(q, big.NewInt(1))
+			} else {
+				q.Add(q, big.NewInt(1))
+			}
+		}
+	}
+
+	z := &Decimal{scale: scale}
+	z.unscaled.Set(q)
+	return z, nil
+}
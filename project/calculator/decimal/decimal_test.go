@@ -0,0 +1,112 @@
+package decimal
+
+import (
+	"errors"
+	"testing"
+)
+
+func mustParse(t *testing.T, s string) *Decimal {
+	t.Helper()
+	d, err := Parse(s)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", s, err)
+	}
+	return d
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"0", "0"},
+		{"42", "42"},
+		{"-42", "-42"},
+		{"+7", "7"},
+		{"1.50", "1.50"},
+		{"-0.05", "-0.05"},
+		{".5", "0.5"},
+		{"5.", "5"},
+		{"007.10", "7.10"},
+		{"123456789012345678901234567890.123456789", "123456789012345678901234567890.123456789"},
+	}
+	for _, tt := range tests {
+		if got := mustParse(t, tt.in).String(); got != tt.want {
+			t.Errorf("Parse(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, s := range []string{"", "-", ".", "1e3", "1.2.3", "--1", " 1", "0x10", "1,5"} {
+		if d, err := Parse(s); err == nil {
+			t.Errorf("Parse(%q) = %v, want an error", s, d)
+		}
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	tests := []struct {
+		op   string
+		f    func(x, y *Decimal) *Decimal
+		x, y string
+		want string
+	}{
+		{"Add", Add, "1.1", "2.25", "3.35"},
+		{"Add", Add, "0.1", "0.2", "0.3"},
+		{"Add", Add, "-1.5", "1.5", "0.0"},
+		{"Sub", Sub, "1", "1.50", "-0.50"},
+		{"Sub", Sub, "10.00", "0.01", "9.99"},
+		{"Mul", Mul, "1.5", "-2.25", "-3.375"},
+		{"Mul", Mul, "0.1", "0.1", "0.01"},
+	}
+	for _, tt := range tests {
+		if got := tt.f(mustParse(t, tt.x), mustParse(t, tt.y)).String(); got != tt.want {
+			t.Errorf("%s(%s, %s) = %s, want %s", tt.op, tt.x, tt.y, got, tt.want)
+		}
+	}
+}
+
+func TestQuo(t *testing.T) {
+	tests := []struct {
+		x, y  string
+		scale int32
+		want  string
+	}{
+		{"1", "3", 5, "0.33333"},
+		{"2", "3", 5, "0.66667"},
+		{"-2", "3", 5, "-0.66667"},
+		{"10", "4", 0, "2"},
+		{"1", "4", 2, "0.25"},
+		{"1.00", "8", 3, "0.125"},
+		// Ties round to the even neighbour.
+		{"1", "8", 2, "0.12"},
+		{"3", "8", 2, "0.38"},
+		{"-1", "8", 2, "-0.12"},
+		{"-3", "8", 2, "-0.38"},
+		{"1", "-8", 2, "-0.12"},
+		{"5", "2", 0, "2"},
+		{"7", "2", 0, "4"},
+		{"2.5", "1", 0, "2"},
+		{"0.35", "1", 1, "0.4"},
+		{"0.45", "1", 1, "0.4"},
+		// Just off a tie rounds to the nearest.
+		{"0.1251", "1", 2, "0.13"},
+		{"0.1249", "1", 2, "0.12"},
+	}
+	for _, tt := range tests {
+		got, err := Quo(mustParse(t, tt.x), mustParse(t, tt.y), tt.scale)
+		if err != nil {
+			t.Errorf("Quo(%s, %s, %d): %v", tt.x, tt.y, tt.scale, err)
+			continue
+		}
+		if got.String() != tt.want {
+			t.Errorf("Quo(%s, %s, %d) = %s, want %s", tt.x, tt.y, tt.scale, got, tt.want)
+		}
+	}
+}
+
+func TestQuoByZero(t *testing.T) {
+	if _, err := Quo(mustParse(t, "1"), mustParse(t, "0.00"), 2); !errors.Is(err, ErrDivisionByZero) {
+		t.Errorf("Quo(1, 0.00) = %v, want ErrDivisionByZero", err)
+	}
+}
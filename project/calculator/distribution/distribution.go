@@ -0,0 +1,277 @@
+// Package distribution samples from and evaluates the uniform, normal,
+// Poisson and exponential probability distributions.
+package distribution
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// Distribution is a probability distribution over the reals.
+type Distribution interface {
+	// Sample draws a value using r.
+	Sample(r *rand.Rand) float64
+	// PDF returns the probability density at x or, for a discrete
+	// distribution, the probability of x.
+	PDF(x float64) float64
+	// CDF returns the probability of a value at most x.
+	CDF(x float64) float64
+}
+
+// ParameterError reports an invalid parameter of a distribution.
+type ParameterError struct {
+	Name string
+	Msg  string
+}
+
+func (e *ParameterError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Name, e.Msg)
+}
+
+func finite(v float64) bool {
+	return !math.IsInf(v, 0) && !math.IsNaN(v)
+}
+
+// positive checks that the parameter name is positive and finite.
+func positive(name string, v float64) error {
+	if !finite(v) || v <= 0 {
+		return &ParameterError{Name: name, Msg: fmt.Sprintf("must be a positive number, got %v", v)}
+	}
+	return nil
+}
+
+// Uniform is the continuous uniform distribution on [Min, Max).
+type Uniform struct {
+	Min, Max float64
+}
+
+// NewUniform returns the uniform distribution on [min, max).
+func NewUniform(min, max float64) (*Uniform, error) {
+	if !finite(min) {
+		return nil, &ParameterError{Name: "min", Msg: fmt.Sprintf("%v is not a finite number", min)}
+	}
+	if !finite(max) || max <= min {
+		return nil, &ParameterError{Name: "max", Msg: fmt.Sprintf("must be a finite number greater than min (%v), got %v", min, max)}
+	}
+	return &Uniform{Min: min, Max: max}, nil
+}
+
+func (d *Uniform) Sample(r *rand.Rand) float64 {
+	return d.Min + r.Float64()*(d.Max-d.Min)
+}
+
+func (d *Uniform) PDF(x float64) float64 {
+	if x < d.Min || x >= d.Max {
+		return 0
+	}
+	return 1 / (d.Max - d.Min)
+}
+
+func (d *Uniform) CDF(x float64) float64 {
+	switch {
+	case x <= d.Min:
+		return 0
+	case x >= d.Max:
+		return 1
+	}
+	return (x - d.Min) / (d.Max - d.Min)
+}
+
+// Normal is the normal distribution.
+type Normal struct {
+	Mean, StdDev float64
+}
+
+// NewNormal returns the normal distribution with the given mean and
+// standard deviation.
+func NewNormal(mean, stddev float64) (*Normal, error) {
+	if !finite(mean) {
+		return nil, &ParameterError{Name: "mean", Msg: fmt.Sprintf("%v is not a finite number", mean)}
+	}
+	if err := positive("stddev", stddev); err != nil {
+		return nil, err
+	}
+	return &Normal{Mean: mean, StdDev: stddev}, nil
+}
+
+func (d *Normal) Sample(r *rand.Rand) float64 {
+	return d.Mean + r.NormFloat64()*d.StdDev
+}
+
+func (d *Normal) PDF(x float64) float64 {
+	z := (x - d.Mean) / d.StdDev
+	return math.Exp(-z*z/2) / (d.StdDev * math.Sqrt(2*math.Pi))
+}
+
+func (d *Normal) CDF(x float64) float64 {
+	return math.Erfc(-(x-d.Mean)/(d.StdDev*math.Sqrt2)) / 2
+}
+
+// Exponential is the exponential distribution with the given rate.
+type Exponential struct {
+	Rate float64
+}
+
+// NewExponential returns the exponential distribution with the given rate.
+func NewExponential(rate float64) (*Exponential, error) {
+	if err := positive("rate", rate); err != nil {
+		return nil, err
+	}
+	return &Exponential{Rate: rate}, nil
+}
+
+func (d *Exponential) Sample(r *rand.Rand) float64 {
+	return r.ExpFloat64() / d.Rate
+}
+
+func (d *Exponential) PDF(x float64) float64 {
+	if x < 0 {
+		return 0
+	}
+	return d.Rate * math.Exp(-d.Rate*x)
+}
+
+func (d *Exponential) CDF(x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	return -math.Expm1(-d.Rate * x)
+}
+
+// MaxPoissonLambda bounds the mean of a Poisson distribution.
+const MaxPoissonLambda = 1e9
+
+// poissonApproxLambda is the mean beyond which the Poisson CDF is computed by
+// the Wilson–Hilferty approximation, which is then within 1e-7, rather than
+// by gammaQ, whose cost grows with the square root of the mean.
+const poissonApproxLambda = 1e5
+
+// Poisson is the Poisson distribution with mean Lambda.
+type Poisson struct {
+	Lambda float64
+}
+
+// NewPoisson returns the Poisson distribution with mean lambda.
+func NewPoisson(lambda float64) (*Poisson, error) {
+	if err := positive("lambda", lambda); err != nil {
+		return nil, err
+	}
+	if lambda > MaxPoissonLambda {
+		return nil, &ParameterError{Name: "lambda", Msg: fmt.Sprintf("must be at most %v, got %v", MaxPoissonLambda, lambda)}
+	}
+	return &Poisson{Lambda: lambda}, nil
+}
+
+// Sample uses Knuth's multiplication method for small means and Hörmann's
+// transformed rejection (PTRS) for large ones.
+func (d *Poisson) Sample(r *rand.Rand) float64 {
+	if d.Lambda < 10 {
+		limit, p := math.Exp(-d.Lambda), 1.0
+		k := 0.0
+		for {
+			p *= r.Float64()
+			if p <= limit {
+				return k
+			}
+			k++
+		}
+	}
+
+	sqrtLambda, logLambda := math.Sqrt(d.Lambda), math.Log(d.Lambda)
+	b := 0.931 + 2.53*sqrtLambda
+	a := -0.059 + 0.02483*b
+	invAlpha := 1.1239 + 1.1328/(b-3.4)
+	vr := 0.9277 - 3.6224/(b-2)
+	for {
+		u := r.Float64() - 0.5
+		v := r.Float64()
+		us := 0.5 - math.Abs(u)
+		k := math.Floor((2*a/us+b)*u + d.Lambda + 0.43)
+		if us >= 0.07 && v <= vr {
+			return k
+		}
+		if k < 0 || (us < 0.013 && v > us) {
+			continue
+		}
+		lg, _ := math.Lgamma(k + 1)
+		if math.Log(v)+math.Log(invAlpha)-math.Log(a/(us*us)+b) <= -d.Lambda+k*logLambda-lg {
+			return k
+		}
+	}
+}
+
+func (d *Poisson) PDF(x float64) float64 {
+	if x < 0 || x != math.Trunc(x) || math.IsInf(x, 1) {
+		return 0
+	}
+	lg, _ := math.Lgamma(x + 1)
+	return math.Exp(x*math.Log(d.Lambda) - d.Lambda - lg)
+}
+
+func (d *Poisson) CDF(x float64) float64 {
+	switch {
+	case x < 0:
+		return 0
+	case math.IsInf(x, 1):
+		return 1
+	}
+	a := math.Floor(x) + 1
+	if d.Lambda > poissonApproxLambda {
+		// P(X ≤ x) is the chance that a Gamma(a) variable exceeds Lambda,
+		// whose cube root is nearly normal.
+		z := 3 * math.Sqrt(a) * (math.Cbrt(d.Lambda/a) - 1 + 1/(9*a))
+		return 0.5 * math.Erfc(z/math.Sqrt2)
+	}
+	return gammaQ(a, d.Lambda)
+}
+
+// gammaQ returns the regularized upper incomplete gamma function Q(a, x),
+// by its series for x < a+1 and its continued fraction otherwise.
+func gammaQ(a, x float64) float64 {
+	const (
+		maxIterations = 1000000
+		epsilon       = 1e-15
+		tiny          = 1e-300
+	)
+	lg, _ := math.Lgamma(a)
+	prefactor := math.Exp(-x + a*math.Log(x) - lg)
+
+	if x < a+1 {
+		sum := 1 / a
+		term := sum
+		for n := 1; n < maxIterations; n++ {
+			term *= x / (a + float64(n))
+			sum += term
+			if math.Abs(term) < math.Abs(sum)*epsilon {
+				break
+			}
+		}
+		return math.Max(0, 1-sum*prefactor)
+	}
+
+	// Lentz's method.
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+	for i := 1; i < maxIterations; i++ {
+		an := -float64(i) * (float64(i) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < epsilon {
+			break
+		}
+	}
+	return prefactor * h
+}
@@ -0,0 +1,45 @@
+package distribution
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPoissonCDF(t *testing.T) {
+	tests := []struct {
+		lambda, x, want float64
+	}{
+		{1, -1, 0},
+		{1, 0, math.Exp(-1)},
+		{1, 1.5, 2 * math.Exp(-1)},
+		{4, 2, 13 * math.Exp(-4)},
+		{4, math.Inf(1), 1},
+	}
+	for _, tt := range tests {
+		d, err := NewPoisson(tt.lambda)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := d.CDF(tt.x); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("Poisson(%v).CDF(%v) = %v, want %v", tt.lambda, tt.x, got, tt.want)
+		}
+	}
+}
+
+// TestPoissonCDFApproximation checks the approximation used for large means
+// against the exact computation.
+func TestPoissonCDFApproximation(t *testing.T) {
+	for _, lambda := range []float64{2 * poissonApproxLambda, 1e6} {
+		d, err := NewPoisson(lambda)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sd := math.Sqrt(lambda)
+		for x := lambda - 6*sd; x <= lambda+6*sd; x += sd / 4 {
+			got, want := d.CDF(x), gammaQ(math.Floor(x)+1, lambda)
+			if math.Abs(got-want) > 1e-7 {
+				t.Errorf("Poisson(%v).CDF(%v) = %v, want %v", lambda, x, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,461 @@
+package expr
+
+import "math"
+
+// Derive returns the derivative of n with respect to the variable x,
+// simplified. Every other variable, and the constants pi and e, are treated
+// as constants. Errors are of type *Error and point at the part of n that
+// cannot be differentiated, such as min(x, 1).
+func Derive(n Node, x string) (Node, error) {
+	d, err := derive(n, x)
+	if err != nil {
+		return nil, err
+	}
+	return Simplify(d), nil
+}
+
+func derive(n Node, x string) (Node, error) {
+	switch n := n.(type) {
+	case *Number:
+		return numNode(0), nil
+	case *Variable:
+		if n.Name == x {
+			return numNode(1), nil
+		}
+		return numNode(0), nil
+	case *Unary:
+		d, err := derive(n.X, x)
+		if err != nil {
+			return nil, err
+		}
+		if n.Op == '-' {
+			return negNode(d), nil
+		}
+		return d, nil
+	case *Binary:
+		u, v := n.X, n.Y
+		du, err := derive(u, x)
+		if err != nil {
+			return nil, err
+		}
+		dv, err := derive(v, x)
+		if err != nil {
+			return nil, err
+		}
+		switch n.Op {
+		case '+':
+			return addNode(du, dv), nil
+		case '-':
+			return subNode(du, dv), nil
+		case '*':
+			return addNode(mulNode(du, v), mulNode(u, dv)), nil
+		case '/':
+			if !depends(v, x) {
+				return divNode(du, v), nil
+			}
+			return divNode(subNode(mulNode(du, v), mulNode(u, dv)), powNode(v, numNode(2))), nil
+		case '%':
+			// u % c differs from u by a step function, whose derivative is
+			// zero wherever it is defined.
+			if depends(v, x) {
+				return nil, errorf(n.Column, "cannot differentiate %% by an expression in %s", x)
+			}
+			return du, nil
+		case '^':
+			return powRule(u, v, du, dv, x), nil
+		}
+		return nil, errorf(n.Column, "unknown operator %q", n.Op)
+	case *Call:
+		if !depends(n, x) {
+			return numNode(0), nil
+		}
+		if n.Func == "pow" {
+			du, err := derive(n.Args[0], x)
+			if err != nil {
+				return nil, err
+			}
+			dv, err := derive(n.Args[1], x)
+			if err != nil {
+				return nil, err
+			}
+			return powRule(n.Args[0], n.Args[1], du, dv, x), nil
+		}
+		if len(n.Args) != 1 {
+			return nil, errorf(n.Column, "cannot differentiate %s", n.Func)
+		}
+		u := n.Args[0]
+		du, err := derive(u, x)
+		if err != nil {
+			return nil, err
+		}
+		switch n.Func {
+		case "sqrt":
+			return divNode(du, mulNode(numNode(2), callNode("sqrt", u))), nil
+		case "abs":
+			return mulNode(du, divNode(u, callNode("abs", u))), nil
+		case "sin":
+			return mulNode(du, callNode("cos", u)), nil
+		case "cos":
+			return negNode(mulNode(du, callNode("sin", u))), nil
+		case "tan":
+			return divNode(du, powNode(callNode("cos", u), numNode(2))), nil
+		case "exp":
+			return mulNode(du, callNode("exp", u)), nil
+		case "ln":
+			return divNode(du, u), nil
+		}
+		return nil, errorf(n.Column, "cannot differentiate %s", n.Func)
+	}
+	return nil, errorf(n.Pos(), "cannot differentiate %v", n)
+}
+
+// powRule differentiates u^v given du and dv.
+func powRule(u, v, du, dv Node, x string) Node {
+	switch {
+	case !depends(v, x):
+		return mulNode(mulNode(v, powNode(u, subNode(v, numNode(1)))), du)
+	case !depends(u, x):
+		factor := callNode("ln", u)
+		if isVariable(u, "e") {
+			factor = numNode(1)
+		}
+		return mulNode(mulNode(dv, factor), powNode(u, v))
+	}
+	return mulNode(powNode(u, v), addNode(mulNode(dv, callNode("ln", u)), divNode(mulNode(v, du), u)))
+}
+
+// depends reports whether n refers to the variable x.
+func depends(n Node, x string) bool {
+	switch n := n.(type) {
+	case *Variable:
+		return n.Name == x
+	case *Unary:
+		return depends(n.X, x)
+	case *Binary:
+		return depends(n.X, x) || depends(n.Y, x)
+	case *Call:
+		for _, arg := range n.Args {
+			if depends(arg, x) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Simplify rewrites n into an equivalent, usually smaller, expression: it
+// folds constant subexpressions and removes identities such as x*1 and x+0.
+func Simplify(n Node) Node {
+	switch n := n.(type) {
+	case *Unary:
+		x := Simplify(n.X)
+		if n.Op == '-' {
+			return negNode(x)
+		}
+		return x
+	case *Binary:
+		x, y := Simplify(n.X), Simplify(n.Y)
+		switch n.Op {
+		case '+':
+			return addNode(x, y)
+		case '-':
+			return subNode(x, y)
+		case '*':
+			return mulNode(x, y)
+		case '/':
+			return divNode(x, y)
+		case '^':
+			return powNode(x, y)
+		}
+		return fold(&Binary{Op: n.Op, X: x, Y: y, Column: n.Column})
+	case *Call:
+		args := make([]Node, len(n.Args))
+		for i, arg := range n.Args {
+			args[i] = Simplify(arg)
+		}
+		return callNode(n.Func, args...)
+	}
+	return n
+}
+
+// The constructors below build a node from already simplified operands,
+// simplifying the result.
+
+// numNode returns the constant v. A negative constant is a negated Number, as
+// the parser would produce.
+func numNode(v float64) Node {
+	if v < 0 || (v == 0 && math.Signbit(v)) {
+		return &Unary{Op: '-', X: &Number{Value: -v}}
+	}
+	return &Number{Value: v}
+}
+
+// constant returns the value of n if it is a number or a negated number.
+func constant(n Node) (float64, bool) {
+	switch n := n.(type) {
+	case *Number:
+		return n.Value, true
+	case *Unary:
+		if x, ok := n.X.(*Number); ok && n.Op == '-' {
+			return -x.Value, true
+		}
+	}
+	return 0, false
+}
+
+func isConstant(n Node, v float64) bool {
+	c, ok := constant(n)
+	return ok && c == v
+}
+
+func isVariable(n Node, name string) bool {
+	v, ok := n.(*Variable)
+	return ok && v.Name == name
+}
+
+// negated returns x if n is -x.
+func negated(n Node) (Node, bool) {
+	if u, ok := n.(*Unary); ok && u.Op == '-' {
+		return u.X, true
+	}
+	return nil, false
+}
+
+// equal reports whether a and b are the same expression.
+func equal(a, b Node) bool {
+	switch a := a.(type) {
+	case *Number:
+		b, ok := b.(*Number)
+		return ok && a.Value == b.Value
+	case *Variable:
+		b, ok := b.(*Variable)
+		return ok && a.Name == b.Name
+	case *Unary:
+		b, ok := b.(*Unary)
+		return ok && a.Op == b.Op && equal(a.X, b.X)
+	case *Binary:
+		b, ok := b.(*Binary)
+		return ok && a.Op == b.Op && equal(a.X, b.X) && equal(a.Y, b.Y)
+	case *Call:
+		b, ok := b.(*Call)
+		if !ok || a.Func != b.Func || len(a.Args) != len(b.Args) {
+			return false
+		}
+		for i := range a.Args {
+			if !equal(a.Args[i], b.Args[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// binary returns n as a *Binary if it applies op.
+func binary(n Node, op byte) (*Binary, bool) {
+	b, ok := n.(*Binary)
+	return b, ok && b.Op == op
+}
+
+// fold evaluates n if all its operands are constants and it has a finite
+// value, and returns it unchanged otherwise. Calls are only folded to whole
+// numbers, so that ln(2) is not replaced by an approximation.
+func fold(n Node) Node {
+	switch n := n.(type) {
+	case *Binary:
+		if _, ok := constant(n.X); !ok {
+			return n
+		}
+		if _, ok := constant(n.Y); !ok {
+			return n
+		}
+	case *Call:
+		for _, arg := range n.Args {
+			if _, ok := constant(arg); !ok {
+				return n
+			}
+		}
+	default:
+		return n
+	}
+	v, err := Eval(n, nil)
+	if err != nil {
+		return n
+	}
+	if _, ok := n.(*Call); ok && v != math.Trunc(v) {
+		return n
+	}
+	return numNode(v)
+}
+
+func negNode(x Node) Node {
+	if c, ok := constant(x); ok {
+		return numNode(-c)
+	}
+	if inner, ok := negated(x); ok {
+		return inner
+	}
+	if b, ok := x.(*Binary); ok {
+		switch b.Op {
+		case '-':
+			return subNode(b.Y, b.X)
+		case '*', '/':
+			if c, ok := constant(b.X); ok {
+				return &Binary{Op: b.Op, X: numNode(-c), Y: b.Y}
+			}
+		}
+	}
+	return &Unary{Op: '-', X: x}
+}
+
+func addNode(a, b Node) Node {
+	switch {
+	case isConstant(a, 0):
+		return b
+	case isConstant(b, 0):
+		return a
+	case equal(a, b):
+		return mulNode(numNode(2), a)
+	}
+	if x, ok := negated(b); ok {
+		return subNode(a, x)
+	}
+	if x, ok := negated(a); ok {
+		return subNode(b, x)
+	}
+	// (u - v) + v is u.
+	if d, ok := binary(a, '-'); ok && equal(d.Y, b) {
+		return d.X
+	}
+	return fold(&Binary{Op: '+', X: a, Y: b})
+}
+
+func subNode(a, b Node) Node {
+	switch {
+	case isConstant(b, 0):
+		return a
+	case isConstant(a, 0):
+		return negNode(b)
+	case equal(a, b):
+		return numNode(0)
+	}
+	if x, ok := negated(b); ok {
+		return addNode(a, x)
+	}
+	// (u + v) - v is u, and (u + v) - u is v.
+	if s, ok := binary(a, '+'); ok {
+		switch {
+		case equal(s.Y, b):
+			return s.X
+		case equal(s.X, b):
+			return s.Y
+		}
+	}
+	return fold(&Binary{Op: '-', X: a, Y: b})
+}
+
+func mulNode(a, b Node) Node {
+	switch {
+	case isConstant(a, 0), isConstant(b, 0):
+		return numNode(0)
+	case isConstant(a, 1):
+		return b
+	case isConstant(b, 1):
+		return a
+	case isConstant(a, -1):
+		return negNode(b)
+	case isConstant(b, -1):
+		return negNode(a)
+	}
+	if _, ok := constant(a); !ok {
+		if _, ok := constant(b); ok {
+			// Keep constant factors on the left.
+			a, b = b, a
+		}
+	}
+	if _, ok := constant(a); !ok {
+		if x, ok := negated(a); ok {
+			return negNode(mulNode(x, b))
+		}
+	}
+	if _, ok := constant(b); !ok {
+		if x, ok := negated(b); ok {
+			return negNode(mulNode(a, x))
+		}
+	}
+	// Gather constant factors into one coefficient: c*(d*u) is (c*d)*u and
+	// u*(d*v) is d*u*v.
+	if inner, ok := binary(b, '*'); ok {
+		if d, ok := constant(inner.X); ok {
+			if c, ok := constant(a); ok {
+				return mulNode(numNode(c*d), inner.Y)
+			}
+			return mulNode(mulNode(inner.X, a), inner.Y)
+		}
+	}
+	if equal(a, b) {
+		return powNode(a, numNode(2))
+	}
+	return fold(&Binary{Op: '*', X: a, Y: b})
+}
+
+func divNode(a, b Node) Node {
+	switch {
+	case isConstant(b, 1):
+		return a
+	case isConstant(b, -1):
+		return negNode(a)
+	case isConstant(a, 0) && !isConstant(b, 0):
+		return numNode(0)
+	case equal(a, b) && !isConstant(b, 0):
+		return numNode(1)
+	}
+	if _, ok := constant(a); !ok {
+		if x, ok := negated(a); ok {
+			return negNode(divNode(x, b))
+		}
+	}
+	if _, ok := constant(b); !ok {
+		if x, ok := negated(b); ok {
+			return negNode(divNode(a, x))
+		}
+	}
+	// (c*u)/d is (c/d)*u.
+	if d, ok := constant(b); ok && d != 0 {
+		if inner, ok := binary(a, '*'); ok {
+			if c, ok := constant(inner.X); ok {
+				return mulNode(numNode(c/d), inner.Y)
+			}
+		}
+	}
+	return fold(&Binary{Op: '/', X: a, Y: b})
+}
+
+func powNode(a, b Node) Node {
+	switch {
+	case isConstant(b, 0):
+		return numNode(1)
+	case isConstant(b, 1):
+		return a
+	case isConstant(a, 1):
+		return numNode(1)
+	}
+	// (u^m)^n is u^(m*n) for integers m and n.
+	if inner, ok := binary(a, '^'); ok {
+		m, mok := constant(inner.Y)
+		n, nok := constant(b)
+		if mok && nok && m == math.Trunc(m) && n == math.Trunc(n) {
+			return powNode(inner.X, numNode(m*n))
+		}
+	}
+	return fold(&Binary{Op: '^', X: a, Y: b})
+}
+
+func callNode(name string, args ...Node) Node {
+	if name == "ln" {
+		if inner, ok := args[0].(*Call); ok && inner.Func == "exp" {
+			return inner.Args[0]
+		}
+	}
+	return fold(&Call{Func: name, Args: args})
+}
@@ -0,0 +1,140 @@
+package expr
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDerive(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"5", "0"},
+		{"y", "0"},
+		{"x", "1"},
+		{"-x", "-1"},
+		{"2*x + 1", "2"},
+		{"x^3", "3 * x^2"},
+		{"x^-1", "-x^-2"},
+		{"a*x^2", "2 * a * x"},
+		{"1/x", "-1 / x^2"},
+		{"sin(x) * x", "cos(x) * x + sin(x)"},
+		{"cos(x^2)", "-(2 * x * sin(x^2))"},
+		{"exp(2*x)", "2 * exp(2 * x)"},
+		{"ln(x)", "1 / x"},
+		{"sqrt(x)", "1 / (2 * sqrt(x))"},
+		{"x^x", "x^x * (ln(x) + 1)"},
+	}
+	for _, tt := range tests {
+		n, err := Parse(tt.in)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.in, err)
+			continue
+		}
+		d, err := Derive(n, "x")
+		if err != nil {
+			t.Errorf("Derive(%q): %v", tt.in, err)
+			continue
+		}
+		if got := Format(d); got != tt.want {
+			t.Errorf("Derive(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// TestDeriveNumerically compares derivatives with central differences, which
+// catches wrong rules whatever shape the simplified result takes.
+func TestDeriveNumerically(t *testing.T) {
+	exprs := []string{
+		"(x+1)/(x-1)",
+		"x^3 - 2*x^2 + x - 7",
+		"sin(x)^2 + cos(x)^2",
+		"tan(x) * exp(-x)",
+		"ln(x^2 + 1) / sqrt(x)",
+		"abs(x - 3) * x",
+		"2^x",
+		"x^x^0.5",
+		"pow(x, 3) - pow(2, x)",
+		"-(x - 1)^-2 % 5",
+	}
+	const h = 1e-6
+	for _, s := range exprs {
+		n, err := Parse(s)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", s, err)
+			continue
+		}
+		d, err := Derive(n, "x")
+		if err != nil {
+			t.Errorf("Derive(%q): %v", s, err)
+			continue
+		}
+		for _, x := range []float64{0.7, 1.9, 4.2} {
+			f := func(x float64) float64 {
+				v, _ := Eval(n, map[string]float64{"x": x})
+				return v
+			}
+			want := (f(x+h) - f(x-h)) / (2 * h)
+			got, err := Eval(d, map[string]float64{"x": x})
+			if err != nil || math.Abs(got-want) > 1e-5*math.Max(1, math.Abs(want)) {
+				t.Errorf("d/dx %s at %v = %v, %v (%s), want about %v", s, x, got, err, Format(d), want)
+			}
+		}
+	}
+}
+
+func TestDeriveUnsupported(t *testing.T) {
+	for _, s := range []string{"max(x, 1)", "min(x, 2, 3)"} {
+		n, err := Parse(s)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", s, err)
+		}
+		if d, err := Derive(n, "x"); err == nil {
+			t.Errorf("Derive(%q) = %s, want an error", s, Format(d))
+		}
+	}
+}
+
+// TestFormat checks that Format keeps only the parentheses the grammar needs
+// and that its output parses back to the same value.
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"(1 + 2) * 3", "(1 + 2) * 3"},
+		{"1 + (2 * 3)", "1 + 2 * 3"},
+		{"1 - (2 - 3)", "1 - (2 - 3)"},
+		{"(1 - 2) - 3", "1 - 2 - 3"},
+		{"2 / (3 * 4)", "2 / (3 * 4)"},
+		{"(2^3)^2", "(2^3)^2"},
+		{"2^(3^2)", "2^3^2"},
+		{"(-2)^2", "(-2)^2"},
+		{"-(2^2)", "-2^2"},
+		{"-(-x)", "--x"},
+		{"2^(-x)", "2^-x"},
+		{"max((1), (2 + 3))", "max(1, 2 + 3)"},
+		{"1e3 + .5", "1000 + 0.5"},
+	}
+	vars := map[string]float64{"x": 1.5}
+	for _, tt := range tests {
+		n, err := Parse(tt.in)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.in, err)
+			continue
+		}
+		got := Format(n)
+		if got != tt.want {
+			t.Errorf("Format(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+		back, err := Parse(got)
+		if err != nil {
+			t.Errorf("Parse(Format(%q)): %v", tt.in, err)
+			continue
+		}
+		v1, _ := Eval(n, vars)
+		v2, _ := Eval(back, vars)
+		if v1 != v2 {
+			t.Errorf("Format(%q) = %q evaluates to %v, want %v", tt.in, got, v2, v1)
+		}
+	}
+}
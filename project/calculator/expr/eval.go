@@ -0,0 +1,225 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Node is a node of an expression tree.
+type Node interface {
+	// Pos returns the 1-based column the node starts at in the source.
+	Pos() int
+	// String renders the node back to source form, fully parenthesised.
+	String() string
+}
+
+// Number is a numeric literal.
+type Number struct {
+	Value  float64
+	Column int
+}
+
+// Variable is a reference to a named value.
+type Variable struct {
+	Name   string
+	Column int
+}
+
+// Unary is a prefix "-" or "+".
+type Unary struct {
+	Op     byte
+	X      Node
+	Column int
+}
+
+// Binary is one of + - * / % ^ applied to X and Y.
+type Binary struct {
+	Op     byte
+	X, Y   Node
+	Column int
+}
+
+// Call is a call of one of the built-in functions.
+type Call struct {
+	Func   string
+	Args   []Node
+	Column int
+}
+
+func (n *Number) Pos() int   { return n.Column }
+func (n *Variable) Pos() int { return n.Column }
+func (n *Unary) Pos() int    { return n.Column }
+func (n *Binary) Pos() int   { return n.X.Pos() }
+func (n *Call) Pos() int     { return n.Column }
+
+func (n *Number) String() string   { return strconv.FormatFloat(n.Value, 'g', -1, 64) }
+func (n *Variable) String() string { return n.Name }
+func (n *Unary) String() string    { return fmt.Sprintf("(%c%v)", n.Op, n.X) }
+func (n *Binary) String() string   { return fmt.Sprintf("(%v %c %v)", n.X, n.Op, n.Y) }
+func (n *Call) String() string {
+	args := make([]string, len(n.Args))
+	for i, arg := range n.Args {
+		args[i] = arg.String()
+	}
+	return n.Func + "(" + strings.Join(args, ", ") + ")"
+}
+
+// constants are available to every expression unless a variable of the same
+// name is supplied.
+var constants = map[string]float64{
+	"pi": math.Pi,
+	"e":  math.E,
+}
+
+type function struct {
+	minArgs, maxArgs int // maxArgs < 0 means variadic
+	eval             func(call *Call, args []float64) (float64, error)
+}
+
+func (f function) arity() string {
+	n := fmt.Sprintf("%d argument", f.minArgs)
+	if f.minArgs != 1 {
+		n += "s"
+	}
+	if f.maxArgs < 0 {
+		return "at least " + n
+	}
+	return n
+}
+
+var functions = map[string]function{
+	"sqrt": {1, 1, func(call *Call, args []float64) (float64, error) {
+		if args[0] < 0 {
+			return 0, errorf(call.Column, "sqrt of negative number %v", args[0])
+		}
+		return math.Sqrt(args[0]), nil
+	}},
+	"abs": {1, 1, func(call *Call, args []float64) (float64, error) {
+		return math.Abs(args[0]), nil
+	}},
+	"min": {1, -1, func(call *Call, args []float64) (float64, error) {
+		v := args[0]
+		for _, a := range args[1:] {
+			v = math.Min(v, a)
+		}
+		return v, nil
+	}},
+	"max": {1, -1, func(call *Call, args []float64) (float64, error) {
+		v := args[0]
+		for _, a := range args[1:] {
+			v = math.Max(v, a)
+		}
+		return v, nil
+	}},
+	"pow": {2, 2, func(call *Call, args []float64) (float64, error) {
+		return pow(call.Column, args[0], args[1])
+	}},
+	"sin": {1, 1, func(call *Call, args []float64) (float64, error) {
+		return math.Sin(args[0]), nil
+	}},
+	"cos": {1, 1, func(call *Call, args []float64) (float64, error) {
+		return math.Cos(args[0]), nil
+	}},
+	"tan": {1, 1, func(call *Call, args []float64) (float64, error) {
+		return math.Tan(args[0]), nil
+	}},
+	"exp": {1, 1, func(call *Call, args []float64) (float64, error) {
+		return math.Exp(args[0]), nil
+	}},
+	"ln": {1, 1, func(call *Call, args []float64) (float64, error) {
+		if args[0] <= 0 {
+			return 0, errorf(call.Column, "ln of non-positive number %v", args[0])
+		}
+		return math.Log(args[0]), nil
+	}},
+}
+
+// Eval evaluates n with the given variables. Errors are of type *Error and
+// point at the offending part of the expression.
+func Eval(n Node, vars map[string]float64) (float64, error) {
+	v, err := eval(n, vars)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsInf(v, 0) || math.IsNaN(v) {
+		return 0, errorf(n.Pos(), "result is not a finite number")
+	}
+	return v, nil
+}
+
+func eval(n Node, vars map[string]float64) (float64, error) {
+	switch n := n.(type) {
+	case *Number:
+		return n.Value, nil
+	case *Variable:
+		if v, ok := vars[n.Name]; ok {
+			return v, nil
+		}
+		if v, ok := constants[n.Name]; ok {
+			return v, nil
+		}
+		return 0, errorf(n.Column, "undefined variable %q", n.Name)
+	case *Unary:
+		x, err := eval(n.X, vars)
+		if err != nil {
+			return 0, err
+		}
+		if n.Op == '-' {
+			return -x, nil
+		}
+		return x, nil
+	case *Binary:
+		x, err := eval(n.X, vars)
+		if err != nil {
+			return 0, err
+		}
+		y, err := eval(n.Y, vars)
+		if err != nil {
+			return 0, err
+		}
+		switch n.Op {
+		case '+':
+			return x + y, nil
+		case '-':
+			return x - y, nil
+		case '*':
+			return x * y, nil
+		case '/':
+			if y == 0 {
+				return 0, errorf(n.Column, "division by zero")
+			}
+			return x / y, nil
+		case '%':
+			if y == 0 {
+				return 0, errorf(n.Column, "modulo by zero")
+			}
+			return math.Mod(x, y), nil
+		case '^':
+			return pow(n.Column, x, y)
+		}
+		return 0, errorf(n.Column, "unknown operator %q", n.Op)
+	case *Call:
+		args := make([]float64, len(n.Args))
+		for i, arg := range n.Args {
+			v, err := eval(arg, vars)
+			if err != nil {
+				return 0, err
+			}
+			args[i] = v
+		}
+		return functions[n.Func].eval(n, args)
+	}
+	return 0, fmt.Errorf("expr: unknown node %T", n)
+}
+
+func pow(column int, x, y float64) (float64, error) {
+	if x < 0 && y != math.Trunc(y) {
+		return 0, errorf(column, "negative number %v raised to non-integer power %v", x, y)
+	}
+	if x == 0 && y < 0 {
+		return 0, errorf(column, "zero raised to negative power %v", y)
+	}
+	return math.Pow(x, y), nil
+}
@@ -0,0 +1,73 @@
+package expr
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestEval(t *testing.T) {
+	vars := map[string]float64{"x": 3, "y": 4}
+	tests := []struct {
+		in   string
+		want float64
+	}{
+		{"1 + 2 * 3", 7},
+		{"(1 + 2) * 3", 9},
+		{"10 - 4 - 3", 3},
+		{"2 * 3 / 4", 1.5},
+		{"10 % 4", 2},
+		{"-2^2", -4},
+		{"2^3^2", 512},
+		{"2^-1", 0.5},
+		{"--3", 3},
+		{"1e3 + .5", 1000.5},
+		{"x * y", 12},
+		{"sqrt(x^2 + y^2)", 5},
+		{"min(3, 1, 2) + max(x, y)", 5},
+		{"abs(-x)", 3},
+		{"pow(2, 10)", 1024},
+		{"pi", math.Pi},
+		{"e", math.E},
+		{"ln(e^2)", 2},
+		{"exp(0) + cos(0) + sin(0) + tan(0)", 2},
+	}
+	for _, tt := range tests {
+		n, err := Parse(tt.in)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.in, err)
+			continue
+		}
+		got, err := Eval(n, vars)
+		if err != nil || math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("Eval(%q) = %v, %v, want %v", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	tests := []struct {
+		in     string
+		column int
+		msg    string
+	}{
+		{"1 / 0", 3, "division by zero"},
+		{"1 + 5 % 0", 7, "modulo by zero"},
+		{"ln(0)", 1, "ln of non-positive number"},
+		{"2 * sqrt(-1)", 5, "sqrt of negative number"},
+		{"z + 1", 1, `undefined variable "z"`},
+	}
+	for _, tt := range tests {
+		n, err := Parse(tt.in)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.in, err)
+			continue
+		}
+		_, err = Eval(n, nil)
+		var exprErr *Error
+		if !errors.As(err, &exprErr) || exprErr.Column != tt.column || !strings.Contains(exprErr.Msg, tt.msg) {
+			t.Errorf("Eval(%q) = %v, want column %d: %s", tt.in, err, tt.column, tt.msg)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package expr
+
+import "strings"
+
+// Binding strengths, from loosest to tightest, following the grammar.
+const (
+	precSum = iota + 1
+	precProduct
+	precUnary
+	precPower
+	precPrimary
+)
+
+func precedence(n Node) int {
+	switch n := n.(type) {
+	case *Unary:
+		return precUnary
+	case *Binary:
+		switch n.Op {
+		case '+', '-':
+			return precSum
+		case '^':
+			return precPower
+		}
+		return precProduct
+	}
+	return precPrimary
+}
+
+// Format renders n in source form with only the parentheses it needs, so
+// that Parse(Format(n)) yields an equivalent tree.
+func Format(n Node) string {
+	var b strings.Builder
+	format(&b, n)
+	return b.String()
+}
+
+func format(b *strings.Builder, n Node) {
+	switch n := n.(type) {
+	case *Unary:
+		b.WriteByte(n.Op)
+		formatOperand(b, n.X, precedence(n.X) < precUnary)
+	case *Binary:
+		p := precedence(n)
+		left, right := precedence(n.X) < p, precedence(n.Y) <= p
+		if n.Op == '^' {
+			// The base of a power is a primary, while its exponent may be
+			// any unary expression.
+			left, right = precedence(n.X) < precPrimary, precedence(n.Y) < precUnary
+		}
+		formatOperand(b, n.X, left)
+		if n.Op == '^' {
+			b.WriteByte('^')
+		} else {
+			b.WriteByte(' ')
+			b.WriteByte(n.Op)
+			b.WriteByte(' ')
+		}
+		formatOperand(b, n.Y, right)
+	case *Call:
+		b.WriteString(n.Func)
+		b.WriteByte('(')
+		for i, arg := range n.Args {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			format(b, arg)
+		}
+		b.WriteByte(')')
+	default:
+		b.WriteString(n.String())
+	}
+}
+
+func formatOperand(b *strings.Builder, n Node, parenthesise bool) {
+	if parenthesise {
+		b.WriteByte('(')
+	}
+	format(b, n)
+	if parenthesise {
+		b.WriteByte(')')
+	}
+}
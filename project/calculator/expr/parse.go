@@ -0,0 +1,335 @@
+// Package expr parses and evaluates infix arithmetic expressions such as
+// "2 * (x + 1) ^ 2 - sqrt(y)".
+//
+// The grammar, from loosest to tightest binding:
+//
+//	expr    = term { ("+" | "-") term }
+//	term    = unary { ("*" | "/" | "%") unary }
+//	unary   = ("-" | "+") unary | power
+//	power   = primary [ "^" unary ]
+//	primary = number | name | name "(" [ expr { "," expr } ] ")" | "(" expr ")"
+//
+// so "^" is right-associative and binds tighter than unary minus: -2^2 is -4.
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Error is a parse or evaluation error at a 1-based column of the input.
+type Error struct {
+	Column int
+	Msg    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("column %d: %s", e.Column, e.Msg)
+}
+
+func errorf(column int, format string, args ...interface{}) *Error {
+	return &Error{Column: column, Msg: fmt.Sprintf(format, args...)}
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokName
+	tokOp // one of + - * / % ^ ( ) ,
+)
+
+type token struct {
+	kind   tokenKind
+	text   string
+	column int
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "end of expression"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// lex splits s into tokens, recording the column each one starts at.
+func lex(s string) ([]token, error) {
+	var tokens []token
+	column := 1
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		start, startColumn := i, column
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+			column++
+			continue
+		case r >= '0' && r <= '9' || r == '.':
+			i = scanNumber(s, i)
+			tokens = append(tokens, token{tokNumber, s[start:i], startColumn})
+		case r == '_' || unicode.IsLetter(r):
+			for i < len(s) {
+				r, size := utf8.DecodeRuneInString(s[i:])
+				if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+					break
+				}
+				i += size
+			}
+			tokens = append(tokens, token{tokName, s[start:i], startColumn})
+		case r < utf8.RuneSelf && isOp(byte(r)):
+			i += size
+			tokens = append(tokens, token{tokOp, s[start:i], startColumn})
+		default:
+			return nil, errorf(column, "unexpected character %q", r)
+		}
+		column += utf8.RuneCountInString(s[start:i])
+	}
+	return append(tokens, token{tokEOF, "", column}), nil
+}
+
+// IsName reports whether s can be used as a variable name in an expression.
+func IsName(s string) bool {
+	for i, r := range s {
+		if r != '_' && !unicode.IsLetter(r) && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func isOp(c byte) bool {
+	switch c {
+	case '+', '-', '*', '/', '%', '^', '(', ')', ',':
+		return true
+	}
+	return false
+}
+
+// scanNumber returns the end of the number starting at s[i], which may have a
+// fraction and an exponent such as 1.5e-3.
+func scanNumber(s string, i int) int {
+	digits := func() {
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i++
+		}
+	}
+	digits()
+	if i < len(s) && s[i] == '.' {
+		i++
+		digits()
+	}
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		j := i + 1
+		if j < len(s) && (s[j] == '+' || s[j] == '-') {
+			j++
+		}
+		if j < len(s) && s[j] >= '0' && s[j] <= '9' {
+			i = j
+			digits()
+		}
+	}
+	return i
+}
+
+const (
+	// MaxLength bounds the length in bytes of the expressions Parse accepts.
+	MaxLength = 64 << 10
+
+	// MaxDepth bounds how deeply Parse lets parentheses, function calls,
+	// signs and exponents nest, so that neither parsing nor walking the tree
+	// can exhaust the stack.
+	MaxDepth = 256
+)
+
+type parser struct {
+	tokens []token
+	pos    int
+	depth  int
+}
+
+// Parse parses s into an expression tree. Expressions longer than MaxLength
+// or nested deeper than MaxDepth are rejected. Errors are of type *Error.
+func Parse(s string) (Node, error) {
+	if len(s) > MaxLength {
+		return nil, errorf(utf8.RuneCountInString(s[:MaxLength])+1, "expression is longer than %d bytes", MaxLength)
+	}
+	tokens, err := lex(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	n, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, errorf(t.column, "unexpected %v", t)
+	}
+	return n, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// acceptOp consumes the next token if it is one of the operators in ops.
+func (p *parser) acceptOp(ops string) (token, bool) {
+	t := p.peek()
+	if t.kind == tokOp && len(t.text) == 1 {
+		for i := 0; i < len(ops); i++ {
+			if t.text[0] == ops[i] {
+				return p.next(), true
+			}
+		}
+	}
+	return t, false
+}
+
+func (p *parser) expect(op string) error {
+	if _, ok := p.acceptOp(op); !ok {
+		t := p.peek()
+		return errorf(t.column, "expected %q, found %v", op, t)
+	}
+	return nil
+}
+
+func (p *parser) expr() (Node, error) {
+	x, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.acceptOp("+-")
+		if !ok {
+			return x, nil
+		}
+		y, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		x = &Binary{Op: op.text[0], X: x, Y: y, Column: op.column}
+	}
+}
+
+func (p *parser) term() (Node, error) {
+	x, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.acceptOp("*/%")
+		if !ok {
+			return x, nil
+		}
+		y, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		x = &Binary{Op: op.text[0], X: x, Y: y, Column: op.column}
+	}
+}
+
+// unary is on every path by which the grammar recurses, so it is where the
+// nesting depth is counted.
+func (p *parser) unary() (Node, error) {
+	if p.depth == MaxDepth {
+		return nil, errorf(p.peek().column, "expression is nested more than %d levels deep", MaxDepth)
+	}
+	p.depth++
+	defer func() { p.depth-- }()
+
+	if op, ok := p.acceptOp("+-"); ok {
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &Unary{Op: op.text[0], X: x, Column: op.column}, nil
+	}
+	return p.power()
+}
+
+func (p *parser) power() (Node, error) {
+	x, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	if op, ok := p.acceptOp("^"); ok {
+		y, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &Binary{Op: '^', X: x, Y: y, Column: op.column}, nil
+	}
+	return x, nil
+}
+
+func (p *parser) primary() (Node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNumber:
+		v, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, errorf(t.column, "invalid number %q", t.text)
+		}
+		return &Number{Value: v, Column: t.column}, nil
+	case tokName:
+		if _, ok := p.acceptOp("("); !ok {
+			return &Variable{Name: t.text, Column: t.column}, nil
+		}
+		call := &Call{Func: t.text, Column: t.column}
+		if _, ok := p.acceptOp(")"); ok {
+			return call, checkArity(call)
+		}
+		for {
+			arg, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			call.Args = append(call.Args, arg)
+			if _, ok := p.acceptOp(","); !ok {
+				break
+			}
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return call, checkArity(call)
+	case tokOp:
+		if t.text == "(" {
+			x, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return x, nil
+		}
+	}
+	return nil, errorf(t.column, "unexpected %v", t)
+}
+
+// checkArity rejects calls to unknown functions or with the wrong number of
+// arguments at parse time, so evaluation only has to deal with values.
+func checkArity(call *Call) error {
+	f, ok := functions[call.Func]
+	if !ok {
+		return errorf(call.Column, "unknown function %q", call.Func)
+	}
+	n := len(call.Args)
+	if n < f.minArgs || (f.maxArgs >= 0 && n > f.maxArgs) {
+		return errorf(call.Column, "%s expects %s, got %d", call.Func, f.arity(), n)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package expr
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		in     string
+		column int
+		msg    string
+	}{
+		{"", 1, "unexpected end of expression"},
+		{"2 +", 4, "unexpected end of expression"},
+		{"1 $ 2", 3, "unexpected character '$'"},
+		{"((1)", 5, `expected ")", found end of expression`},
+		{"1 2", 3, `unexpected "2"`},
+		{"foo(1)", 1, `unknown function "foo"`},
+		{"1 + sqrt(1, 2)", 5, "sqrt expects 1 argument, got 2"},
+		{"pow(2)", 1, "pow expects 2 arguments, got 1"},
+		{"é + )", 5, `unexpected ")"`},
+		{strings.Repeat("(", MaxDepth+1) + "1" + strings.Repeat(")", MaxDepth+1), MaxDepth + 1, "nested more than"},
+		{strings.Repeat("-", MaxDepth+1) + "1", MaxDepth + 1, "nested more than"},
+		{strings.Repeat("2^", MaxDepth+1) + "1", 2*MaxDepth + 1, "nested more than"},
+		{strings.Repeat("(", 1<<20) + "1", MaxLength + 1, "longer than"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.in)
+		var exprErr *Error
+		if !errors.As(err, &exprErr) {
+			t.Errorf("Parse(%.20q) = %v, want an *Error", tt.in, err)
+			continue
+		}
+		if exprErr.Column != tt.column || !strings.Contains(exprErr.Msg, tt.msg) {
+			t.Errorf("Parse(%.20q) = %v, want column %d: %s", tt.in, err, tt.column, tt.msg)
+		}
+	}
+}
+
+func TestParseLimits(t *testing.T) {
+	for _, s := range []string{
+		strings.Repeat("(", MaxDepth-1) + "1" + strings.Repeat(")", MaxDepth-1),
+		strings.Repeat("1+", MaxLength/2-1) + "1",
+	} {
+		if _, err := Parse(s); err != nil {
+			t.Errorf("Parse(%.20q...) of %d bytes: %v", s, len(s), err)
+		}
+	}
+}
+
+func TestIsName(t *testing.T) {
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{"x", true},
+		{"_tmp", true},
+		{"x2", true},
+		{"λ", true},
+		{"", false},
+		{"2x", false},
+		{"a-b", false},
+		{"a b", false},
+	}
+	for _, tt := range tests {
+		if got := IsName(tt.in); got != tt.want {
+			t.Errorf("IsName(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
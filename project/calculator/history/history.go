@@ -0,0 +1,342 @@
+// Package history keeps an audit log of calculator calls: who called which
+// method, with what, and what they got back.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// MaxFieldLength bounds the Request, Response and Error of a recorded
+	// entry; longer ones are cut and the entry marked Truncated.
+	MaxFieldLength = 4 << 10
+
+	// maxLineLength bounds the lines NewFileStore reads. Files written before
+	// entries were truncated may hold long lines; longer ones are skipped.
+	maxLineLength = 16 << 20
+
+	// entryOverhead approximates the memory of an entry besides its strings.
+	entryOverhead = 128
+)
+
+// Entry records one call.
+type Entry struct {
+	Method   string        `json:"method"`
+	Time     time.Time     `json:"time"`
+	Duration time.Duration `json:"duration"`
+	Caller   string        `json:"caller"`
+	Session  string        `json:"session,omitempty"`
+	// Request and Response hold the messages as JSON. For streaming calls
+	// they are arrays of the first messages exchanged.
+	Request  string `json:"request"`
+	Response string `json:"response,omitempty"`
+	Code     uint32 `json:"code"`
+	Error    string `json:"error,omitempty"`
+	// Truncated is set if Request, Response or Error was cut to
+	// MaxFieldLength bytes, so it may not be valid JSON.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// truncate cuts the long fields of e to MaxFieldLength bytes.
+func (e *Entry) truncate() {
+	for _, field := range []*string{&e.Request, &e.Response, &e.Error} {
+		if len(*field) <= MaxFieldLength {
+			continue
+		}
+		cut := MaxFieldLength
+		for cut > 0 && !utf8.RuneStart((*field)[cut]) {
+			cut--
+		}
+		*field = (*field)[:cut]
+		e.Truncated = true
+	}
+}
+
+// size approximates the memory e takes.
+func (e *Entry) size() int {
+	return entryOverhead + len(e.Method) + len(e.Caller) + len(e.Session) +
+		len(e.Request) + len(e.Response) + len(e.Error)
+}
+
+// Filter selects entries. Zero fields match everything.
+type Filter struct {
+	Method       string
+	Caller       string
+	Since, Until time.Time
+}
+
+func (f Filter) match(e Entry) bool {
+	return (f.Method == "" || e.Method == f.Method) &&
+		(f.Caller == "" || e.Caller == f.Caller) &&
+		(f.Since.IsZero() || !e.Time.Before(f.Since)) &&
+		(f.Until.IsZero() || e.Time.Before(f.Until))
+}
+
+// Retention bounds what a Store keeps. Zero fields impose no bound.
+type Retention struct {
+	MaxEntries int
+	MaxAge     time.Duration
+	// MaxBytes bounds the approximate memory the entries take.
+	MaxBytes int
+}
+
+// Store records entries. Implementations must be safe for concurrent use.
+type Store interface {
+	// Record appends e, with its long fields truncated to MaxFieldLength.
+	// Entries are kept in the order recorded, which for concurrent calls is
+	// roughly but not strictly by Time.
+	Record(e Entry) error
+	// Query returns the entries matching f in the order recorded.
+	Query(f Filter) ([]Entry, error)
+}
+
+// MemoryStore keeps entries in memory; they are lost when the server stops.
+type MemoryStore struct {
+	retention Retention
+
+	mu sync.Mutex
+	// entries[head:] are kept; pruning advances head rather than copying,
+	// and the slice is compacted once most of it is dropped.
+	entries []Entry
+	head    int
+	bytes   int
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore(retention Retention) *MemoryStore {
+	return &MemoryStore{retention: retention}
+}
+
+func (m *MemoryStore) Record(e Entry) error {
+	e.truncate()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.record(e)
+	return nil
+}
+
+// record keeps e, which must already be truncated. m.mu must be held.
+func (m *MemoryStore) record(e Entry) {
+	m.entries = append(m.entries, e)
+	m.bytes += e.size()
+	m.prune(e.Time)
+}
+
+// kept returns the entries the store holds. m.mu must be held.
+func (m *MemoryStore) kept() []Entry {
+	return m.entries[m.head:]
+}
+
+// prune drops the entries the retention no longer covers. m.mu must be held.
+func (m *MemoryStore) prune(now time.Time) {
+	var oldest time.Time
+	if m.retention.MaxAge > 0 {
+		oldest = now.Add(-m.retention.MaxAge)
+	}
+	for m.head < len(m.entries) {
+		e := &m.entries[m.head]
+		expired := (m.retention.MaxEntries > 0 && len(m.entries)-m.head > m.retention.MaxEntries) ||
+			(m.retention.MaxBytes > 0 && m.bytes > m.retention.MaxBytes) ||
+			(!oldest.IsZero() && e.Time.Before(oldest))
+		if !expired {
+			break
+		}
+		m.bytes -= e.size()
+		*e = Entry{} // release its strings
+		m.head++
+	}
+	if m.head > 0 && m.head >= len(m.entries)/2 {
+		n := copy(m.entries, m.entries[m.head:])
+		for i := n; i < len(m.entries); i++ {
+			m.entries[i] = Entry{}
+		}
+		m.entries = m.entries[:n]
+		m.head = 0
+	}
+}
+
+func (m *MemoryStore) Query(f Filter) ([]Entry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.prune(time.Now())
+	var list []Entry
+	for _, e := range m.kept() {
+		if f.match(e) {
+			list = append(list, e)
+		}
+	}
+	return list, nil
+}
+
+// FileStore is a MemoryStore that appends every entry to a file of JSON lines,
+// so the history survives restarts. The file is rewritten without expired
+// entries when the store is opened and whenever it has grown to twice what
+// the retention allows.
+type FileStore struct {
+	*MemoryStore
+	path         string
+	file         *os.File
+	written      int
+	writtenBytes int
+}
+
+// NewFileStore loads the history saved at path. A missing file starts empty.
+func NewFileStore(path string, retention Retention) (*FileStore, error) {
+	f := &FileStore{MemoryStore: NewMemoryStore(retention), path: path}
+	file, err := os.Open(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		err := f.load(bufio.NewReader(file))
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", path, err)
+		}
+	}
+
+	f.prune(time.Now())
+	if err := f.compact(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// load reads the entries of a file of JSON lines. Lines longer than
+// maxLineLength, and a last line cut short by a crash, are skipped; long
+// fields of the others are truncated.
+func (f *FileStore) load(r *bufio.Reader) error {
+	for line := 1; ; line++ {
+		b, tooLong, err := readLine(r, maxLineLength)
+		if err == io.EOF && len(b) == 0 {
+			return nil
+		}
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if tooLong || len(b) == 0 {
+			continue
+		}
+		var e Entry
+		if jsonErr := json.Unmarshal(b, &e); jsonErr != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("line %d: %w", line, jsonErr)
+		}
+		e.truncate()
+		f.record(e)
+	}
+}
+
+// readLine reads a line without its newline. If the line is longer than max
+// bytes, the rest of it is discarded and tooLong is set. err is io.EOF if
+// the input ended before a newline.
+func readLine(r *bufio.Reader, max int) (line []byte, tooLong bool, err error) {
+	for {
+		chunk, err := r.ReadSlice('\n')
+		if !tooLong {
+			if len(line)+len(chunk) > max+1 {
+				tooLong, line = true, nil
+			} else {
+				line = append(line, chunk...)
+			}
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err == nil && !tooLong {
+			line = line[:len(line)-1]
+		}
+		return line, tooLong, err
+	}
+}
+
+func (f *FileStore) Record(e Entry) error {
+	e.truncate()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record(e)
+
+	if max := f.retention.MaxEntries; max > 0 && f.written >= 2*max {
+		return f.compact()
+	}
+	if max := f.retention.MaxBytes; max > 0 && f.writtenBytes >= 2*max {
+		return f.compact()
+	}
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if _, err := f.file.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	f.written++
+	f.writtenBytes += e.size()
+	return nil
+}
+
+// Close closes the underlying file.
+func (f *FileStore) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Close()
+}
+
+// compact writes the kept entries to a temporary file, renames it over the
+// old one and reopens it for appending. f.mu must be held, or f not yet
+// shared.
+func (f *FileStore) compact() error {
+	// TempFile creates files only their owner can read; keep the mode of
+	// the file replaced, or 0644 for a new one.
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(f.path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(f.path), filepath.Base(f.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(w)
+	for _, e := range f.kept() {
+		if err = enc.Encode(e); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = tmp.Chmod(mode)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), f.path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if f.file != nil {
+		f.file.Close()
+	}
+	if f.file, err = os.OpenFile(f.path, os.O_WRONLY|os.O_APPEND, 0644); err != nil {
+		return err
+	}
+	f.written, f.writtenBytes = len(f.kept()), f.bytes
+	return nil
+}
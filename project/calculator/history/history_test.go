@@ -0,0 +1,141 @@
+package history
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTruncate(t *testing.T) {
+	// "é" is two bytes, so MaxFieldLength falls inside one.
+	e := Entry{Request: "x" + strings.Repeat("é", MaxFieldLength), Response: "{}"}
+	e.truncate()
+	if !e.Truncated || len(e.Request) != MaxFieldLength-1 || e.Response != "{}" {
+		t.Errorf("truncated to %d bytes, response %q, Truncated %v", len(e.Request), e.Response, e.Truncated)
+	}
+
+	e = Entry{Request: strings.Repeat("x", MaxFieldLength)}
+	if e.truncate(); e.Truncated {
+		t.Errorf("an entry of exactly MaxFieldLength bytes was truncated")
+	}
+}
+
+func TestMemoryStoreRetention(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name      string
+		retention Retention
+		want      int // entries kept of the 100 recorded
+	}{
+		{"unbounded", Retention{}, 100},
+		{"entries", Retention{MaxEntries: 30}, 30},
+		{"age", Retention{MaxAge: 10*time.Minute - time.Second}, 10},
+		{"bytes", Retention{MaxBytes: 20 * (entryOverhead + 1)}, 20},
+	}
+	for _, tt := range tests {
+		m := NewMemoryStore(tt.retention)
+		for i := 0; i < 100; i++ {
+			m.Record(Entry{Method: "m", Time: now.Add(time.Duration(i-99) * time.Minute)})
+		}
+		list, _ := m.Query(Filter{})
+		if len(list) != tt.want {
+			t.Errorf("%s: kept %d entries, want %d", tt.name, len(list), tt.want)
+			continue
+		}
+		if !list[len(list)-1].Time.Equal(now) {
+			t.Errorf("%s: the last entry kept is from %v, want the newest", tt.name, list[len(list)-1].Time)
+		}
+		if len(m.entries) > 2*tt.want {
+			t.Errorf("%s: %d entries held for %d kept", tt.name, len(m.entries), tt.want)
+		}
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "history")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "history.jsonl")
+
+	now := time.Now().UTC().Truncate(time.Second)
+	long := `{"x":"` + strings.Repeat("y", 2*MaxFieldLength) + `"}`
+	content := `{"method":"A","time":"` + now.Format(time.RFC3339) + `","request":"` + strings.ReplaceAll(long, `"`, `\"`) + `"}` + "\n" +
+		`{"method":"B","time":"` + now.Format(time.RFC3339) + `","request":"` + strings.Repeat("z", maxLineLength) + `"}` + "\n" +
+		`{"method":"C","time":"` + now.Format(time.RFC3339) + `","request":"{}"}` + "\n" +
+		`{"method":"D","ti`
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Chmod(path, 0640); err != nil {
+		t.Fatal(err)
+	}
+	f, err := NewFileStore(path, Retention{MaxEntries: 3})
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	for _, method := range []string{"E", "F"} {
+		if err := f.Record(Entry{Method: method, Time: now, Request: long}); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+	f.Close()
+
+	// The over-long line B and the torn line D are skipped, and A drops out
+	// of the retention.
+	f, err = NewFileStore(path, Retention{MaxEntries: 3})
+	if err != nil {
+		t.Fatalf("reopening: %v", err)
+	}
+	defer f.Close()
+	list, _ := f.Query(Filter{})
+	var methods []string
+	for _, e := range list {
+		methods = append(methods, e.Method)
+		if len(e.Request) > MaxFieldLength || e.Truncated != (e.Method != "C") {
+			t.Errorf("%s: request of %d bytes, Truncated %v", e.Method, len(e.Request), e.Truncated)
+		}
+	}
+	if got := strings.Join(methods, ""); got != "CEF" {
+		t.Errorf("reopened store holds %s, want CEF", got)
+	}
+	if info, err := os.Stat(path); err != nil {
+		t.Error(err)
+	} else if info.Mode().Perm() != 0640 {
+		t.Errorf("mode after compaction is %v, want 0640", info.Mode().Perm())
+	}
+}
+
+func TestFilter(t *testing.T) {
+	now := time.Now()
+	m := NewMemoryStore(Retention{})
+	for _, e := range []Entry{
+		{Method: "Sum", Caller: "10.0.0.1", Time: now.Add(-2 * time.Hour)},
+		{Method: "Sum", Caller: "10.0.0.2", Time: now.Add(-time.Hour)},
+		{Method: "Evaluate", Caller: "10.0.0.1", Time: now},
+	} {
+		m.Record(e)
+	}
+	tests := []struct {
+		name   string
+		filter Filter
+		want   int
+	}{
+		{"everything", Filter{}, 3},
+		{"method", Filter{Method: "Sum"}, 2},
+		{"caller", Filter{Caller: "10.0.0.1"}, 2},
+		{"caller and method", Filter{Caller: "10.0.0.2", Method: "Evaluate"}, 0},
+		{"since", Filter{Since: now.Add(-time.Hour)}, 2},
+		{"until", Filter{Until: now.Add(-time.Hour)}, 1},
+	}
+	for _, tt := range tests {
+		if list, _ := m.Query(tt.filter); len(list) != tt.want {
+			t.Errorf("%s: %d entries, want %d", tt.name, len(list), tt.want)
+		}
+	}
+}
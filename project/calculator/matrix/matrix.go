@@ -0,0 +1,250 @@
+// Package matrix implements dense linear algebra on float64 matrices.
+package matrix
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrSingular is returned when a matrix has no inverse.
+var ErrSingular = errors.New("matrix is singular")
+
+// MaxElements bounds the results Mul allocates, 32 MiB of float64s, since a
+// product can be far larger than its operands.
+const MaxElements = 1 << 22
+
+// ShapeError reports an operand whose dimensions do not fit the operation.
+type ShapeError struct {
+	Op      string
+	Operand string // "a" or "b"
+	Msg     string
+}
+
+func (e *ShapeError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Op, e.Msg)
+}
+
+// Dense is a rows × cols matrix stored in row-major order.
+type Dense struct {
+	Rows, Cols int
+	Data       []float64
+}
+
+// New returns a zero rows × cols matrix.
+func New(rows, cols int) *Dense {
+	return &Dense{Rows: rows, Cols: cols, Data: make([]float64, rows*cols)}
+}
+
+// At returns the element at row i, column j.
+func (m *Dense) At(i, j int) float64 { return m.Data[i*m.Cols+j] }
+
+// Set sets the element at row i, column j.
+func (m *Dense) Set(i, j int, v float64) { m.Data[i*m.Cols+j] = v }
+
+// Row returns row i, sharing storage with m.
+func (m *Dense) Row(i int) []float64 { return m.Data[i*m.Cols : (i+1)*m.Cols] }
+
+// Mul returns a × b. It checks ctx between rows, as large products are slow.
+func Mul(ctx context.Context, a, b *Dense) (*Dense, error) {
+	if a.Cols != b.Rows {
+		return nil, &ShapeError{Op: "multiply", Operand: "b",
+			Msg: fmt.Sprintf("a has %d columns but b has %d rows", a.Cols, b.Rows)}
+	}
+	if err := CheckProduct(a.Rows, b.Cols); err != nil {
+		return nil, err
+	}
+	c := New(a.Rows, b.Cols)
+	for i := 0; i < a.Rows; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		ci := c.Row(i)
+		for k, aik := range a.Row(i) {
+			if aik == 0 {
+				continue
+			}
+			for j, bkj := range b.Row(k) {
+				ci[j] += aik * bkj
+			}
+		}
+	}
+	return c, nil
+}
+
+// CheckProduct returns a ShapeError if the rows × cols product of a
+// multiplication would have more than MaxElements elements.
+func CheckProduct(rows, cols int) error {
+	if uint64(rows)*uint64(cols) > MaxElements {
+		return &ShapeError{Op: "multiply", Operand: "b",
+			Msg: fmt.Sprintf("the %d×%d product exceeds the limit of %d elements", rows, cols, MaxElements)}
+	}
+	return nil
+}
+
+// Transpose returns the transpose of a.
+func Transpose(a *Dense) *Dense {
+	t := New(a.Cols, a.Rows)
+	for i := 0; i < a.Rows; i++ {
+		for j := 0; j < a.Cols; j++ {
+			t.Set(j, i, a.At(i, j))
+		}
+	}
+	return t
+}
+
+// lu is an LU decomposition with partial pivoting: P·A = L·U, with L and U
+// packed into one matrix and P kept as a row permutation.
+type lu struct {
+	m     *Dense
+	perm  []int
+	sign  float64
+	exact bool // false if a zero pivot was met, i.e. A is singular
+}
+
+func decompose(ctx context.Context, op string, a *Dense) (*lu, error) {
+	if a.Rows != a.Cols {
+		return nil, &ShapeError{Op: op, Operand: "a",
+			Msg: fmt.Sprintf("a must be square, got %d×%d", a.Rows, a.Cols)}
+	}
+	n := a.Rows
+	m := &Dense{Rows: n, Cols: n, Data: append([]float64(nil), a.Data...)}
+	f := &lu{m: m, perm: make([]int, n), sign: 1, exact: true}
+	for i := range f.perm {
+		f.perm[i] = i
+	}
+
+	for k := 0; k < n; k++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		p := k
+		for i := k + 1; i < n; i++ {
+			if math.Abs(m.At(i, k)) > math.Abs(m.At(p, k)) {
+				p = i
+			}
+		}
+		if m.At(p, k) == 0 {
+			f.exact = false
+			continue
+		}
+		if p != k {
+			rp, rk := m.Row(p), m.Row(k)
+			for j := range rk {
+				rp[j], rk[j] = rk[j], rp[j]
+			}
+			f.perm[p], f.perm[k] = f.perm[k], f.perm[p]
+			f.sign = -f.sign
+		}
+		pivot := m.At(k, k)
+		rk := m.Row(k)
+		for i := k + 1; i < n; i++ {
+			ri := m.Row(i)
+			factor := ri[k] / pivot
+			ri[k] = factor
+			for j := k + 1; j < n; j++ {
+				ri[j] -= factor * rk[j]
+			}
+		}
+	}
+	return f, nil
+}
+
+// singular reports whether a pivot of the decomposition of a is zero up to
+// rounding error, in which case solving with it would only amplify noise.
+func (f *lu) singular(a *Dense) bool {
+	if !f.exact {
+		return true
+	}
+	largest := 0.0
+	for _, v := range a.Data {
+		largest = math.Max(largest, math.Abs(v))
+	}
+	tolerance := largest * float64(a.Rows) * epsilon
+	for i := 0; i < f.m.Rows; i++ {
+		if math.Abs(f.m.At(i, i)) <= tolerance {
+			return true
+		}
+	}
+	return false
+}
+
+// epsilon is the float64 machine epsilon.
+const epsilon = 2.220446049250313e-16
+
+// Det returns the determinant of the square matrix a.
+func Det(ctx context.Context, a *Dense) (float64, error) {
+	if a.Rows == 0 && a.Cols == 0 {
+		return 1, nil
+	}
+	f, err := decompose(ctx, "determinant", a)
+	if err != nil {
+		return 0, err
+	}
+	if !f.exact {
+		return 0, nil
+	}
+	det := f.sign
+	for i := 0; i < f.m.Rows; i++ {
+		det *= f.m.At(i, i)
+	}
+	return det, nil
+}
+
+// Solve returns X with a·X = b, for square a and b with as many rows as a.
+func Solve(ctx context.Context, a, b *Dense) (*Dense, error) {
+	if a.Rows != a.Cols {
+		return nil, &ShapeError{Op: "solve", Operand: "a",
+			Msg: fmt.Sprintf("a must be square, got %d×%d", a.Rows, a.Cols)}
+	}
+	if b.Rows != a.Rows {
+		return nil, &ShapeError{Op: "solve", Operand: "b",
+			Msg: fmt.Sprintf("b must have %d rows to match a, got %d", a.Rows, b.Rows)}
+	}
+	f, err := decompose(ctx, "solve", a)
+	if err != nil {
+		return nil, err
+	}
+	if f.singular(a) {
+		return nil, ErrSingular
+	}
+
+	n := a.Rows
+	x := New(n, b.Cols)
+	for i := 0; i < n; i++ {
+		copy(x.Row(i), b.Row(f.perm[i]))
+	}
+	for col := 0; col < b.Cols; col++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		// Forward substitution with the unit lower triangle, then back
+		// substitution with the upper one.
+		for i := 0; i < n; i++ {
+			for k := 0; k < i; k++ {
+				x.Data[i*x.Cols+col] -= f.m.At(i, k) * x.At(k, col)
+			}
+		}
+		for i := n - 1; i >= 0; i-- {
+			for k := i + 1; k < n; k++ {
+				x.Data[i*x.Cols+col] -= f.m.At(i, k) * x.At(k, col)
+			}
+			x.Data[i*x.Cols+col] /= f.m.At(i, i)
+		}
+	}
+	return x, nil
+}
+
+// Inverse returns the inverse of the square matrix a.
+func Inverse(ctx context.Context, a *Dense) (*Dense, error) {
+	if a.Rows != a.Cols {
+		return nil, &ShapeError{Op: "inverse", Operand: "a",
+			Msg: fmt.Sprintf("a must be square, got %d×%d", a.Rows, a.Cols)}
+	}
+	identity := New(a.Rows, a.Rows)
+	for i := 0; i < a.Rows; i++ {
+		identity.Set(i, i, 1)
+	}
+	return Solve(ctx, a, identity)
+}
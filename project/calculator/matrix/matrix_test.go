@@ -0,0 +1,200 @@
+package matrix
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+)
+
+// dense builds a matrix from its rows.
+func dense(rows ...[]float64) *Dense {
+	m := &Dense{Rows: len(rows)}
+	if len(rows) > 0 {
+		m.Cols = len(rows[0])
+	}
+	for _, r := range rows {
+		m.Data = append(m.Data, r...)
+	}
+	return m
+}
+
+func approxEqual(a, b *Dense, tol float64) bool {
+	if a.Rows != b.Rows || a.Cols != b.Cols {
+		return false
+	}
+	for i := range a.Data {
+		if math.Abs(a.Data[i]-b.Data[i]) > tol {
+			return false
+		}
+	}
+	return true
+}
+
+func TestMul(t *testing.T) {
+	tests := []struct {
+		a, b, want *Dense
+	}{
+		{
+			dense([]float64{1, 2}, []float64{3, 4}),
+			dense([]float64{5, 6}, []float64{7, 8}),
+			dense([]float64{19, 22}, []float64{43, 50}),
+		},
+		{
+			dense([]float64{1, 2, 3}),
+			dense([]float64{4}, []float64{5}, []float64{6}),
+			dense([]float64{32}),
+		},
+		{
+			dense([]float64{1}, []float64{2}),
+			dense([]float64{3, 4}),
+			dense([]float64{3, 4}, []float64{6, 8}),
+		},
+	}
+	for _, tt := range tests {
+		got, err := Mul(context.Background(), tt.a, tt.b)
+		if err != nil {
+			t.Errorf("Mul(%v, %v): %v", tt.a, tt.b, err)
+			continue
+		}
+		if !approxEqual(got, tt.want, 0) {
+			t.Errorf("Mul(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestMulShape(t *testing.T) {
+	_, err := Mul(context.Background(), New(2, 3), New(2, 3))
+	var shapeErr *ShapeError
+	if !errors.As(err, &shapeErr) || shapeErr.Operand != "b" {
+		t.Errorf("Mul(2×3, 2×3) = %v, want a ShapeError on b", err)
+	}
+}
+
+func TestMulTooLarge(t *testing.T) {
+	// The outer product of two vectors within the limit would be 320 GB.
+	_, err := Mul(context.Background(), New(200000, 1), New(1, 200000))
+	var shapeErr *ShapeError
+	if !errors.As(err, &shapeErr) || shapeErr.Operand != "b" {
+		t.Errorf("Mul(200000×1, 1×200000) = %v, want a ShapeError on b", err)
+	}
+	if err := CheckProduct(1<<11, 1<<11); err != nil {
+		t.Errorf("CheckProduct(2048, 2048) = %v, want nil", err)
+	}
+}
+
+func TestTranspose(t *testing.T) {
+	got := Transpose(dense([]float64{1, 2, 3}, []float64{4, 5, 6}))
+	want := dense([]float64{1, 4}, []float64{2, 5}, []float64{3, 6})
+	if !approxEqual(got, want, 0) {
+		t.Errorf("Transpose = %v, want %v", got, want)
+	}
+}
+
+func TestDet(t *testing.T) {
+	tests := []struct {
+		a    *Dense
+		want float64
+	}{
+		{New(0, 0), 1},
+		{dense([]float64{7}), 7},
+		{dense([]float64{1, 2}, []float64{3, 4}), -2},
+		// Needs a row swap, which flips the sign.
+		{dense([]float64{0, 1}, []float64{1, 0}), -1},
+		{dense([]float64{2, 0, 0}, []float64{0, 3, 0}, []float64{0, 0, 4}), 24},
+		{dense([]float64{6, 1, 1}, []float64{4, -2, 5}, []float64{2, 8, 7}), -306},
+		{dense([]float64{1, 2}, []float64{2, 4}), 0},
+		{dense([]float64{0, 0}, []float64{0, 0}), 0},
+	}
+	for _, tt := range tests {
+		got, err := Det(context.Background(), tt.a)
+		if err != nil {
+			t.Errorf("Det(%v): %v", tt.a, err)
+			continue
+		}
+		if math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Det(%v) = %v, want %v", tt.a, got, tt.want)
+		}
+	}
+}
+
+func TestSolve(t *testing.T) {
+	tests := []struct {
+		a, b, want *Dense
+	}{
+		{
+			dense([]float64{2, 1}, []float64{1, 3}),
+			dense([]float64{3}, []float64{5}),
+			dense([]float64{0.8}, []float64{1.4}),
+		},
+		{
+			// A zero in the first pivot position.
+			dense([]float64{0, 1, 1}, []float64{1, 0, 1}, []float64{1, 1, 0}),
+			dense([]float64{2, 5}, []float64{2, 7}, []float64{2, 6}),
+			dense([]float64{1, 4}, []float64{1, 2}, []float64{1, 3}),
+		},
+	}
+	for _, tt := range tests {
+		got, err := Solve(context.Background(), tt.a, tt.b)
+		if err != nil {
+			t.Errorf("Solve(%v, %v): %v", tt.a, tt.b, err)
+			continue
+		}
+		if !approxEqual(got, tt.want, 1e-12) {
+			t.Errorf("Solve(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSolveErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    *Dense
+		operand string // of the ShapeError, or "" for ErrSingular
+	}{
+		{"not square", New(2, 3), New(2, 1), "a"},
+		{"mismatched rows", New(2, 2), New(3, 1), "b"},
+		{"singular", dense([]float64{1, 2}, []float64{2, 4}), New(2, 1), ""},
+		{"singular up to rounding", dense([]float64{1, 2, 3}, []float64{4, 5, 6}, []float64{7, 8, 9}), New(3, 1), ""},
+	}
+	for _, tt := range tests {
+		_, err := Solve(context.Background(), tt.a, tt.b)
+		var shapeErr *ShapeError
+		switch {
+		case tt.operand == "" && !errors.Is(err, ErrSingular):
+			t.Errorf("%s: Solve = %v, want ErrSingular", tt.name, err)
+		case tt.operand != "" && (!errors.As(err, &shapeErr) || shapeErr.Operand != tt.operand):
+			t.Errorf("%s: Solve = %v, want a ShapeError on %s", tt.name, err, tt.operand)
+		}
+	}
+}
+
+func TestInverse(t *testing.T) {
+	a := dense([]float64{4, 7, 2}, []float64{3, 6, 1}, []float64{2, 5, 3})
+	inv, err := Inverse(context.Background(), a)
+	if err != nil {
+		t.Fatalf("Inverse: %v", err)
+	}
+	identity := dense([]float64{1, 0, 0}, []float64{0, 1, 0}, []float64{0, 0, 1})
+	for _, product := range [][2]*Dense{{a, inv}, {inv, a}} {
+		got, err := Mul(context.Background(), product[0], product[1])
+		if err != nil {
+			t.Fatalf("Mul: %v", err)
+		}
+		if !approxEqual(got, identity, 1e-12) {
+			t.Errorf("%v × %v = %v, want the identity", product[0], product[1], got)
+		}
+	}
+}
+
+func TestCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	a := dense([]float64{1, 2}, []float64{3, 4})
+	if _, err := Mul(ctx, a, a); !errors.Is(err, context.Canceled) {
+		t.Errorf("Mul = %v, want context.Canceled", err)
+	}
+	if _, err := Det(ctx, a); !errors.Is(err, context.Canceled) {
+		t.Errorf("Det = %v, want context.Canceled", err)
+	}
+}
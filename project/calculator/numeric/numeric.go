@@ -0,0 +1,254 @@
+// Package numeric implements numerical integration, root finding and the
+// solution of ordinary differential equations for functions that may fail
+// to evaluate.
+//
+// Every method takes a tolerance and a limit on the work it does, and fails
+// with a *NotConvergedError when the limit is reached first.
+package numeric
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Func is a function of one variable.
+type Func func(x float64) (float64, error)
+
+// Func2 is a function of two variables, such as the right-hand side of
+// y' = f(t, y).
+type Func2 func(t, y float64) (float64, error)
+
+// NotConvergedError reports a method that did not reach the tolerance within
+// its limit.
+type NotConvergedError struct {
+	Method     string
+	Reason     string
+	Iterations int
+	// Estimate is the best result found, if any.
+	Estimate float64
+}
+
+func (e *NotConvergedError) Error() string {
+	return fmt.Sprintf("%s did not converge after %d iterations: %s", e.Method, e.Iterations, e.Reason)
+}
+
+// ErrNoBracket is returned by Bisect when the function has the same sign at
+// both ends of the interval.
+var ErrNoBracket = errors.New("the function must have opposite signs at the ends of the interval")
+
+// ctxCheckInterval is how many evaluations pass between checks of the
+// context.
+const ctxCheckInterval = 1024
+
+// maxSimpsonDepth bounds the recursion of Integrate, which then reports that
+// it did not converge.
+const maxSimpsonDepth = 50
+
+type integrator struct {
+	ctx      context.Context
+	f        Func
+	evals    int
+	maxEvals int
+	err      error
+	// unresolved is set when an interval reached maxSimpsonDepth without
+	// meeting its tolerance.
+	unresolved bool
+}
+
+func (in *integrator) eval(x float64) float64 {
+	if in.err != nil {
+		return 0
+	}
+	if in.evals >= in.maxEvals {
+		in.err = &NotConvergedError{
+			Method:     "integration",
+			Reason:     fmt.Sprintf("the limit of %d evaluations was reached", in.maxEvals),
+			Iterations: in.evals,
+		}
+		return 0
+	}
+	in.evals++
+	if in.evals%ctxCheckInterval == 0 {
+		if in.err = in.ctx.Err(); in.err != nil {
+			return 0
+		}
+	}
+	v, err := in.f(x)
+	in.err = err
+	return v
+}
+
+// simpson integrates over [a, b], given f at a, b and the midpoint m and the
+// Simpson estimate whole over the interval. It returns the integral and an
+// estimate of its error.
+func (in *integrator) simpson(a, fa, m, fm, b, fb, whole, tol float64, depth int) (float64, float64) {
+	lm, rm := (a+m)/2, (m+b)/2
+	flm, frm := in.eval(lm), in.eval(rm)
+	if in.err != nil {
+		return 0, 0
+	}
+	left := (m - a) / 6 * (fa + 4*flm + fm)
+	right := (b - m) / 6 * (fm + 4*frm + fb)
+	delta := left + right - whole
+	if math.Abs(delta) <= 15*tol {
+		return left + right + delta/15, math.Abs(delta) / 15
+	}
+	if depth == 0 {
+		in.unresolved = true
+		return left + right + delta/15, math.Abs(delta) / 15
+	}
+	l, lerr := in.simpson(a, fa, lm, flm, m, fm, left, tol/2, depth-1)
+	r, rerr := in.simpson(m, fm, rm, frm, b, fb, right, tol/2, depth-1)
+	return l + r, lerr + rerr
+}
+
+// Integrate returns the integral of f from a to b by adaptive Simpson
+// quadrature, an estimate of its absolute error and the number of times f
+// was evaluated. It fails once f has been evaluated maxEvals times.
+func Integrate(ctx context.Context, f Func, a, b, tol float64, maxEvals int) (value, errEstimate float64, evals int, err error) {
+	if a == b {
+		return 0, 0, 0, nil
+	}
+	sign := 1.0
+	if a > b {
+		a, b, sign = b, a, -1
+	}
+	in := &integrator{ctx: ctx, f: f, maxEvals: maxEvals}
+	m := (a + b) / 2
+	fa, fm, fb := in.eval(a), in.eval(m), in.eval(b)
+	if in.err != nil {
+		return 0, 0, in.evals, in.err
+	}
+	whole := (b - a) / 6 * (fa + 4*fm + fb)
+	value, errEstimate = in.simpson(a, fa, m, fm, b, fb, whole, tol, maxSimpsonDepth)
+	if in.err != nil {
+		return 0, 0, in.evals, in.err
+	}
+	if in.unresolved {
+		return 0, 0, in.evals, &NotConvergedError{
+			Method:     "integration",
+			Reason:     "the integrand is too irregular to meet the tolerance",
+			Iterations: in.evals,
+			Estimate:   sign * value,
+		}
+	}
+	return sign * value, errEstimate, in.evals, nil
+}
+
+// Bisect finds a root of f in [a, b], where f(a) and f(b) have opposite
+// signs, to within tol. It returns the root and the number of iterations.
+func Bisect(ctx context.Context, f Func, a, b, tol float64, maxIter int) (float64, int, error) {
+	if a > b {
+		a, b = b, a
+	}
+	fa, err := f(a)
+	if err != nil {
+		return 0, 0, err
+	}
+	if fa == 0 {
+		return a, 0, nil
+	}
+	fb, err := f(b)
+	if err != nil {
+		return 0, 0, err
+	}
+	if fb == 0 {
+		return b, 0, nil
+	}
+	if (fa < 0) == (fb < 0) {
+		return 0, 0, ErrNoBracket
+	}
+	for i := 1; i <= maxIter; i++ {
+		if err := ctx.Err(); err != nil {
+			return 0, i, err
+		}
+		m := a + (b-a)/2
+		if b-a <= 2*tol || m == a || m == b {
+			return m, i, nil
+		}
+		fm, err := f(m)
+		if err != nil {
+			return 0, i, err
+		}
+		if fm == 0 {
+			return m, i, nil
+		}
+		if (fm < 0) == (fa < 0) {
+			a, fa = m, fm
+		} else {
+			b = m
+		}
+	}
+	return 0, maxIter, &NotConvergedError{
+		Method:     "bisection",
+		Reason:     fmt.Sprintf("the interval is still %v wide", b-a),
+		Iterations: maxIter,
+		Estimate:   a + (b-a)/2,
+	}
+}
+
+// Newton finds a root of f starting from x0 by Newton's method, stopping
+// once a step is smaller than tol. If df is nil, the derivative is estimated
+// by central differences. It returns the root and the number of iterations.
+func Newton(ctx context.Context, f, df Func, x0, tol float64, maxIter int) (float64, int, error) {
+	if df == nil {
+		df = centralDifference(f)
+	}
+	x := x0
+	for i := 1; i <= maxIter; i++ {
+		if err := ctx.Err(); err != nil {
+			return 0, i, err
+		}
+		fx, err := f(x)
+		if err != nil {
+			return 0, i, err
+		}
+		if fx == 0 {
+			return x, i, nil
+		}
+		d, err := df(x)
+		if err != nil {
+			return 0, i, err
+		}
+		if d == 0 {
+			return 0, i, &NotConvergedError{
+				Method:     "Newton's method",
+				Reason:     fmt.Sprintf("the derivative is zero at %v", x),
+				Iterations: i,
+				Estimate:   x,
+			}
+		}
+		step := fx / d
+		x -= step
+		if math.IsInf(x, 0) || math.IsNaN(x) {
+			return 0, i, &NotConvergedError{Method: "Newton's method", Reason: "the iteration diverged", Iterations: i}
+		}
+		if math.Abs(step) <= tol {
+			return x, i, nil
+		}
+	}
+	return 0, maxIter, &NotConvergedError{
+		Method:     "Newton's method",
+		Reason:     fmt.Sprintf("the last step was larger than %v", tol),
+		Iterations: maxIter,
+		Estimate:   x,
+	}
+}
+
+// centralDifference estimates the derivative of f.
+func centralDifference(f Func) Func {
+	return func(x float64) (float64, error) {
+		h := 1e-6 * math.Max(1, math.Abs(x))
+		hi, err := f(x + h)
+		if err != nil {
+			return 0, err
+		}
+		lo, err := f(x - h)
+		if err != nil {
+			return 0, err
+		}
+		return (hi - lo) / (2 * h), nil
+	}
+}
@@ -0,0 +1,170 @@
+package numeric
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+)
+
+func pure(f func(float64) float64) Func {
+	return func(x float64) (float64, error) { return f(x), nil }
+}
+
+func TestIntegrate(t *testing.T) {
+	tests := []struct {
+		name string
+		f    Func
+		a, b float64
+		want float64
+	}{
+		{"x^2", pure(func(x float64) float64 { return x * x }), 0, 1, 1.0 / 3},
+		{"sin", pure(math.Sin), 0, math.Pi, 2},
+		{"reversed bounds", pure(math.Sin), math.Pi, 0, -2},
+		{"empty interval", pure(math.Exp), 1, 1, 0},
+		{"exp", pure(math.Exp), 0, 1, math.E - 1},
+		{"1/x", pure(func(x float64) float64 { return 1 / x }), 1, math.E, 1},
+		{"sqrt", pure(math.Sqrt), 0, 1, 2.0 / 3},
+		{"gaussian", pure(func(x float64) float64 { return math.Exp(-x * x) }), -10, 10, math.Sqrt(math.Pi)},
+	}
+	for _, tt := range tests {
+		got, errEstimate, _, err := Integrate(context.Background(), tt.f, tt.a, tt.b, 1e-10, 1e6)
+		if err != nil {
+			t.Errorf("%s: Integrate: %v", tt.name, err)
+			continue
+		}
+		if math.Abs(got-tt.want) > 1e-8 {
+			t.Errorf("%s: Integrate = %v (±%v), want %v", tt.name, got, errEstimate, tt.want)
+		}
+	}
+}
+
+func TestIntegrateNotConverged(t *testing.T) {
+	tests := []struct {
+		name     string
+		f        Func
+		maxEvals int
+	}{
+		{"evaluation limit", pure(math.Sin), 10},
+		{"irregular integrand", pure(func(x float64) float64 { return math.Sin(1 / x) }), 1e6},
+	}
+	for _, tt := range tests {
+		_, _, evals, err := Integrate(context.Background(), tt.f, 0, 1, 1e-12, tt.maxEvals)
+		var nc *NotConvergedError
+		if !errors.As(err, &nc) {
+			t.Errorf("%s: Integrate = %v, want a NotConvergedError", tt.name, err)
+			continue
+		}
+		if nc.Iterations != evals || evals == 0 {
+			t.Errorf("%s: %d iterations reported after %d evaluations", tt.name, nc.Iterations, evals)
+		}
+	}
+}
+
+func TestIntegratePropagatesErrors(t *testing.T) {
+	fail := errors.New("boom")
+	f := func(x float64) (float64, error) {
+		if x > 0.5 {
+			return 0, fail
+		}
+		return x, nil
+	}
+	if _, _, _, err := Integrate(context.Background(), f, 0, 1, 1e-6, 1000); err != fail {
+		t.Errorf("Integrate = %v, want the integrand's error", err)
+	}
+}
+
+func TestRoots(t *testing.T) {
+	tests := []struct {
+		name string
+		f    Func
+		a, b float64 // bracket for Bisect; Newton starts at b
+		want float64
+	}{
+		{"sqrt 2", pure(func(x float64) float64 { return x*x - 2 }), 0, 2, math.Sqrt2},
+		{"cos x = x", pure(func(x float64) float64 { return math.Cos(x) - x }), 0, 1, 0.7390851332151607},
+		{"cubic", pure(func(x float64) float64 { return x*x*x - 2*x - 5 }), 2, 3, 2.0945514815423265},
+		{"root at an end", pure(func(x float64) float64 { return x - 1 }), 1, 2, 1},
+	}
+	for _, tt := range tests {
+		got, _, err := Bisect(context.Background(), tt.f, tt.a, tt.b, 1e-12, 200)
+		if err != nil || math.Abs(got-tt.want) > 1e-11 {
+			t.Errorf("%s: Bisect = %v, %v, want %v", tt.name, got, err, tt.want)
+		}
+		got, _, err = Newton(context.Background(), tt.f, nil, tt.b, 1e-12, 100)
+		if err != nil || math.Abs(got-tt.want) > 1e-11 {
+			t.Errorf("%s: Newton = %v, %v, want %v", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestBisectNoBracket(t *testing.T) {
+	_, _, err := Bisect(context.Background(), pure(func(x float64) float64 { return x*x + 1 }), -1, 1, 1e-9, 100)
+	if !errors.Is(err, ErrNoBracket) {
+		t.Errorf("Bisect = %v, want ErrNoBracket", err)
+	}
+}
+
+func TestNewtonNotConverged(t *testing.T) {
+	tests := []struct {
+		name string
+		f    Func
+		x0   float64
+	}{
+		{"zero derivative", pure(func(x float64) float64 { return x*x + 1 }), 0},
+		// Newton's method cycles between 0 and 1 on this cubic.
+		{"cycle", pure(func(x float64) float64 { return x*x*x - 2*x + 2 }), 0},
+	}
+	for _, tt := range tests {
+		_, _, err := Newton(context.Background(), tt.f, nil, tt.x0, 1e-12, 50)
+		var nc *NotConvergedError
+		if !errors.As(err, &nc) {
+			t.Errorf("%s: Newton = %v, want a NotConvergedError", tt.name, err)
+		}
+	}
+}
+
+func TestSolveODE(t *testing.T) {
+	tests := []struct {
+		name      string
+		f         Func2
+		t0, y0, T float64
+		exact     func(t float64) float64
+	}{
+		{"growth", func(t, y float64) (float64, error) { return y, nil }, 0, 1, 1, math.Exp},
+		{"decay backwards", func(t, y float64) (float64, error) { return -2 * y, nil }, 1, 1, 0,
+			func(t float64) float64 { return math.Exp(-2 * (t - 1)) }},
+		{"forced", func(t, y float64) (float64, error) { return math.Cos(t), nil }, 0, 0, 10, math.Sin},
+		{"logistic", func(t, y float64) (float64, error) { return y * (1 - y), nil }, 0, 0.1, 8,
+			func(t float64) float64 { return 1 / (1 + 9*math.Exp(-t)) }},
+	}
+	for _, tt := range tests {
+		var ts []float64
+		err := SolveODE(context.Background(), tt.f, tt.t0, tt.y0, tt.T, 1e-10, 0.5, 100000, func(at, y float64) error {
+			if want := tt.exact(at); math.Abs(y-want) > 1e-7 {
+				t.Errorf("%s: y(%v) = %v, want %v", tt.name, at, y, want)
+			}
+			ts = append(ts, at)
+			return nil
+		})
+		if err != nil {
+			t.Errorf("%s: SolveODE: %v", tt.name, err)
+			continue
+		}
+		if ts[0] != tt.t0 || ts[len(ts)-1] != tt.T {
+			t.Errorf("%s: emitted times from %v to %v, want %v to %v", tt.name, ts[0], ts[len(ts)-1], tt.t0, tt.T)
+		}
+		if want := int(math.Abs(tt.T-tt.t0)/0.5) + 1; len(ts) != want {
+			t.Errorf("%s: emitted %d points, want %d", tt.name, len(ts), want)
+		}
+	}
+}
+
+func TestSolveODEMaxSteps(t *testing.T) {
+	f := func(t, y float64) (float64, error) { return y, nil }
+	err := SolveODE(context.Background(), f, 0, 1, 100, 1e-12, 0, 10, func(t, y float64) error { return nil })
+	var nc *NotConvergedError
+	if !errors.As(err, &nc) {
+		t.Errorf("SolveODE = %v, want a NotConvergedError", err)
+	}
+}
@@ -0,0 +1,149 @@
+package numeric
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// The Dormand–Prince 5(4) tableau.
+var (
+	dpC = [7]float64{0, 1.0 / 5, 3.0 / 10, 4.0 / 5, 8.0 / 9, 1, 1}
+	dpA = [7][6]float64{
+		{},
+		{1.0 / 5},
+		{3.0 / 40, 9.0 / 40},
+		{44.0 / 45, -56.0 / 15, 32.0 / 9},
+		{19372.0 / 6561, -25360.0 / 2187, 64448.0 / 6561, -212.0 / 729},
+		{9017.0 / 3168, -355.0 / 33, 46732.0 / 5247, 49.0 / 176, -5103.0 / 18656},
+		{35.0 / 384, 0, 500.0 / 1113, 125.0 / 192, -2187.0 / 6784, 11.0 / 84},
+	}
+	// dpE is the difference between the fifth- and fourth-order weights.
+	dpE = [7]float64{
+		35.0/384 - 5179.0/57600,
+		0,
+		500.0/1113 - 7571.0/16695,
+		125.0/192 - 393.0/640,
+		-2187.0/6784 + 92097.0/339200,
+		11.0/84 - 187.0/2100,
+		-1.0 / 40,
+	}
+)
+
+// SolveODE integrates y' = f(t, y) from (t0, y0) to tEnd with the adaptive
+// Dormand–Prince method, keeping the local error of each step within
+// tol*(1+|y|). It calls emit with (t0, y0), then with the solution every
+// interval, or after every step if interval is zero, and finally at tEnd.
+// It fails after maxSteps steps, rejected ones included.
+func SolveODE(ctx context.Context, f Func2, t0, y0, tEnd, tol, interval float64, maxSteps int, emit func(t, y float64) error) error {
+	if err := emit(t0, y0); err != nil {
+		return err
+	}
+	if t0 == tEnd {
+		return nil
+	}
+	dir := 1.0
+	if tEnd < t0 {
+		dir = -1
+	}
+	span := math.Abs(tEnd - t0)
+	h := span / 100
+	if interval > 0 {
+		h = math.Min(h, interval)
+	}
+
+	t, y := t0, y0
+	sample := 1
+	var k [7]float64
+	for steps := 0; ; steps++ {
+		if steps == maxSteps {
+			return &NotConvergedError{
+				Method:     "the ODE solver",
+				Reason:     fmt.Sprintf("the limit of %d steps was reached at t = %v", maxSteps, t),
+				Iterations: steps,
+				Estimate:   y,
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// Step no further than the next sample or the end.
+		target := tEnd
+		if interval > 0 {
+			if next := t0 + dir*float64(sample)*interval; dir*(next-tEnd) < 0 {
+				target = next
+			}
+		}
+		hStep, landing := h, false
+		if remaining := math.Abs(target - t); hStep >= remaining {
+			hStep, landing = remaining, true
+		}
+		if hStep <= 1e-12*math.Max(1, math.Abs(t)) {
+			return &NotConvergedError{
+				Method:     "the ODE solver",
+				Reason:     fmt.Sprintf("the step size became too small at t = %v", t),
+				Iterations: steps,
+				Estimate:   y,
+			}
+		}
+
+		yNext, errEstimate, err := dpStep(f, t, y, dir*hStep, &k)
+		if err != nil {
+			return err
+		}
+		scale := tol * (1 + math.Max(math.Abs(y), math.Abs(yNext)))
+		if errEstimate > scale || math.IsNaN(errEstimate) {
+			h = hStep * math.Max(0.2, 0.9*math.Pow(scale/errEstimate, 0.2))
+			continue
+		}
+
+		if landing {
+			t = target
+		} else {
+			t += dir * hStep
+		}
+		y = yNext
+		if errEstimate == 0 {
+			h = hStep * 5
+		} else {
+			h = hStep * math.Min(5, 0.9*math.Pow(scale/errEstimate, 0.2))
+		}
+		if landing && t == tEnd {
+			return emit(t, y)
+		}
+		if interval == 0 || landing {
+			if err := emit(t, y); err != nil {
+				return err
+			}
+			if landing {
+				sample++
+			}
+		}
+	}
+}
+
+// dpStep takes one Dormand–Prince step of size h from (t, y), returning the
+// fifth-order solution and an estimate of its local error.
+func dpStep(f Func2, t, y, h float64, k *[7]float64) (float64, float64, error) {
+	for i := range k {
+		yi := y
+		for j := 0; j < i; j++ {
+			yi += h * dpA[i][j] * k[j]
+		}
+		v, err := f(t+dpC[i]*h, yi)
+		if err != nil {
+			return 0, 0, err
+		}
+		k[i] = v
+	}
+	yNext := y
+	for j := 0; j < 6; j++ {
+		yNext += h * dpA[6][j] * k[j]
+	}
+	var e float64
+	for i, w := range dpE {
+		e += h * w * k[i]
+	}
+	return yNext, math.Abs(e), nil
+}
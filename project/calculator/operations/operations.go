@@ -0,0 +1,203 @@
+// Package operations runs calculations in the background and keeps their
+// results for a while, so that clients can poll for them.
+package operations
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned for unknown, expired, evicted or foreign
+	// operations.
+	ErrNotFound = errors.New("operation not found or expired")
+
+	// ErrTooManyOperations is returned when the Manager holds as many
+	// operations as it may.
+	ErrTooManyOperations = errors.New("too many operations")
+
+	// ErrTooManyRunning is returned when an owner already runs as many
+	// operations as it may.
+	ErrTooManyRunning = errors.New("too many running operations")
+)
+
+// RunFunc is the calculation of an operation. It must stop when ctx is done.
+type RunFunc func(ctx context.Context) (interface{}, error)
+
+// Operation is a calculation started by a Manager.
+type Operation struct {
+	Name    string
+	Owner   string
+	Method  string
+	Created time.Time
+
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	// Set before done is closed.
+	ended  time.Time
+	result interface{}
+	err    error
+}
+
+// Done returns a channel closed when the operation finishes.
+func (o *Operation) Done() <-chan struct{} { return o.done }
+
+// Result reports whether the operation has finished and, if so, when and with
+// what outcome.
+func (o *Operation) Result() (done bool, ended time.Time, result interface{}, err error) {
+	select {
+	case <-o.done:
+		return true, o.ended, o.result, o.err
+	default:
+		return false, time.Time{}, nil, nil
+	}
+}
+
+// Wait blocks until the operation finishes or ctx is done.
+func (o *Operation) Wait(ctx context.Context) {
+	select {
+	case <-o.done:
+	case <-ctx.Done():
+	}
+}
+
+// Limits bounds the operations a Manager keeps.
+type Limits struct {
+	// Total bounds the operations kept, running or finished.
+	Total int
+	// PerOwner bounds the operations kept for one owner. Starting another
+	// forgets the owner's oldest finished one.
+	PerOwner int
+	// RunningPerOwner bounds the operations one owner runs at once.
+	RunningPerOwner int
+}
+
+// Manager runs operations and forgets them ttl after they finish, or sooner
+// when their owner starts more than its limit.
+type Manager struct {
+	ttl     time.Duration
+	timeout time.Duration
+	limits  Limits
+
+	mu  sync.Mutex
+	ops map[string]*Operation
+}
+
+// NewManager returns a Manager keeping operations within limits. Each runs
+// for at most timeout.
+func NewManager(ttl, timeout time.Duration, limits Limits) *Manager {
+	return &Manager{
+		ttl:     ttl,
+		timeout: timeout,
+		limits:  limits,
+		ops:     make(map[string]*Operation),
+	}
+}
+
+// TTL returns how long finished operations are kept.
+func (m *Manager) TTL() time.Duration { return m.ttl }
+
+// Start runs run in the background as an operation of the given method,
+// started by owner.
+func (m *Manager) Start(owner, method string, run RunFunc) (*Operation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	kept, running := 0, 0
+	var oldest *Operation // the owner's oldest finished operation
+	for name, o := range m.ops {
+		if m.expired(o, now) {
+			delete(m.ops, name)
+			continue
+		}
+		if o.Owner != owner {
+			continue
+		}
+		kept++
+		if done, ended, _, _ := o.Result(); !done {
+			running++
+		} else if oldest == nil || ended.Before(oldest.ended) {
+			oldest = o
+		}
+	}
+	if running >= m.limits.RunningPerOwner {
+		return nil, ErrTooManyRunning
+	}
+	if kept >= m.limits.PerOwner {
+		if oldest == nil {
+			return nil, ErrTooManyOperations
+		}
+		delete(m.ops, oldest.Name)
+	}
+	if len(m.ops) >= m.limits.Total {
+		return nil, ErrTooManyOperations
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+	o := &Operation{
+		Name:    "operations/" + hex.EncodeToString(id),
+		Owner:   owner,
+		Method:  method,
+		Created: now,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+	m.ops[o.Name] = o
+
+	go func() {
+		defer cancel()
+		result, err := run(ctx)
+		if err == nil {
+			// A calculation may ignore ctx near its end; report it as
+			// finished all the same.
+			o.result = result
+		} else if ctxErr := ctx.Err(); ctxErr != nil {
+			err = ctxErr
+		}
+		o.err = err
+		o.ended = time.Now()
+		close(o.done)
+	}()
+	return o, nil
+}
+
+// Get returns the operation with the given name, if owner started it.
+func (m *Manager) Get(owner, name string) (*Operation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	o := m.ops[name]
+	if o == nil || o.Owner != owner {
+		return nil, ErrNotFound
+	}
+	if m.expired(o, time.Now()) {
+		delete(m.ops, name)
+		return nil, ErrNotFound
+	}
+	return o, nil
+}
+
+// Cancel asks the operation with the given name, if owner started it, to
+// stop. It then finishes with context.Canceled, unless it completes first.
+func (m *Manager) Cancel(owner, name string) error {
+	o, err := m.Get(owner, name)
+	if err != nil {
+		return err
+	}
+	o.cancel()
+	return nil
+}
+
+func (m *Manager) expired(o *Operation, now time.Time) bool {
+	done, ended, _, _ := o.Result()
+	return done && now.Sub(ended) > m.ttl
+}
@@ -0,0 +1,165 @@
+package operations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+var limits = Limits{Total: 10, PerOwner: 3, RunningPerOwner: 2}
+
+// value returns a RunFunc finishing at once with v.
+func value(v interface{}) RunFunc {
+	return func(ctx context.Context) (interface{}, error) { return v, nil }
+}
+
+// block returns a RunFunc that runs until ctx is done.
+func block(ctx context.Context) (interface{}, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+// finish starts an operation and waits for it to finish.
+func finish(t *testing.T, m *Manager, owner string, run RunFunc) *Operation {
+	t.Helper()
+	o, err := m.Start(owner, "m", run)
+	if err != nil {
+		t.Fatalf("Start for %s: %v", owner, err)
+	}
+	<-o.Done()
+	return o
+}
+
+func TestResult(t *testing.T) {
+	m := NewManager(time.Hour, time.Hour, limits)
+	o := finish(t, m, "alice", value(42))
+	if done, _, result, err := o.Result(); !done || result != 42 || err != nil {
+		t.Errorf("Result() = %v, %v, %v, want done with 42", done, result, err)
+	}
+
+	fail := errors.New("boom")
+	o = finish(t, m, "alice", func(ctx context.Context) (interface{}, error) { return nil, fail })
+	if _, _, _, err := o.Result(); err != fail {
+		t.Errorf("Result() error = %v, want %v", err, fail)
+	}
+}
+
+func TestCancelAndTimeout(t *testing.T) {
+	m := NewManager(time.Hour, time.Hour, limits)
+	o, err := m.Start("alice", "m", block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if done, _, _, _ := o.Result(); done {
+		t.Fatalf("a blocked operation is done")
+	}
+	if err := m.Cancel("alice", o.Name); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	<-o.Done()
+	if _, _, _, err := o.Result(); !errors.Is(err, context.Canceled) {
+		t.Errorf("canceled operation failed with %v, want context.Canceled", err)
+	}
+
+	m = NewManager(time.Hour, 10*time.Millisecond, limits)
+	o = finish(t, m, "alice", block)
+	if _, _, _, err := o.Result(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("timed out operation failed with %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestOwnership(t *testing.T) {
+	m := NewManager(time.Hour, time.Hour, limits)
+	o, err := m.Start("alice", "m", block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Cancel("alice", o.Name)
+
+	if _, err := m.Get("bob", o.Name); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get by another owner = %v, want ErrNotFound", err)
+	}
+	if err := m.Cancel("bob", o.Name); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Cancel by another owner = %v, want ErrNotFound", err)
+	}
+	if done, _, _, _ := o.Result(); done {
+		t.Errorf("another owner's Cancel stopped the operation")
+	}
+	if got, err := m.Get("alice", o.Name); got != o || err != nil {
+		t.Errorf("Get by the owner = %v, %v", got, err)
+	}
+	if _, err := m.Get("alice", "operations/unknown"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get of an unknown operation = %v, want ErrNotFound", err)
+	}
+}
+
+func TestExpiry(t *testing.T) {
+	m := NewManager(10*time.Millisecond, time.Hour, limits)
+	o := finish(t, m, "alice", value(1))
+	if _, err := m.Get("alice", o.Name); err != nil {
+		t.Fatalf("Get right after finishing: %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if _, err := m.Get("alice", o.Name); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after the TTL = %v, want ErrNotFound", err)
+	}
+}
+
+func TestRunningPerOwner(t *testing.T) {
+	m := NewManager(time.Hour, time.Hour, limits)
+	var names []string
+	defer func() {
+		for _, name := range names {
+			m.Cancel("alice", name)
+		}
+	}()
+	for i := 0; i < limits.RunningPerOwner; i++ {
+		o, err := m.Start("alice", "m", block)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, o.Name)
+	}
+	if _, err := m.Start("alice", "m", block); !errors.Is(err, ErrTooManyRunning) {
+		t.Errorf("Start beyond the running limit = %v, want ErrTooManyRunning", err)
+	}
+	if o, err := m.Start("bob", "m", value(1)); err != nil {
+		t.Errorf("another owner's Start = %v", err)
+	} else {
+		<-o.Done()
+	}
+
+	// Finishing one makes room.
+	m.Cancel("alice", names[0])
+	o, _ := m.Get("alice", names[0])
+	<-o.Done()
+	if o, err := m.Start("alice", "m", value(1)); err != nil {
+		t.Errorf("Start after one finished = %v", err)
+	} else {
+		<-o.Done()
+	}
+}
+
+// TestPerOwner checks that an owner starting many quick operations evicts
+// its own oldest results rather than filling the manager for everyone.
+func TestPerOwner(t *testing.T) {
+	m := NewManager(time.Hour, time.Hour, limits)
+	var ops []*Operation
+	for i := 0; i < 2*limits.Total; i++ {
+		ops = append(ops, finish(t, m, "alice", value(i)))
+	}
+	for i, o := range ops {
+		_, err := m.Get("alice", o.Name)
+		if kept := i >= len(ops)-limits.PerOwner; kept != (err == nil) {
+			t.Errorf("operation %d: Get = %v, want kept %v", i, err, kept)
+		}
+	}
+	for i := 0; i < limits.Total-limits.PerOwner; i++ {
+		finish(t, m, fmt.Sprintf("client%d", i), value(i))
+	}
+	if _, err := m.Start("late", "m", value(1)); !errors.Is(err, ErrTooManyOperations) {
+		t.Errorf("Start with %d operations kept = %v, want ErrTooManyOperations", limits.Total, err)
+	}
+}
@@ -0,0 +1,160 @@
+// Package primes factorizes integers and enumerates primes.
+package primes
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sort"
+)
+
+// millerRabinRounds is how many Miller–Rabin rounds ProbablyPrime runs. Go
+// adds a Baillie-PSW test on top, which has no known counterexample.
+const millerRabinRounds = 20
+
+// smallPrimes are tried by division before falling back to Pollard's rho,
+// which is faster for large factors but has overhead for tiny ones.
+var smallPrimes = sieve(1000)
+
+// ErrNotFactorable is returned for numbers below 2, which have no prime
+// factorization.
+var ErrNotFactorable = errors.New("only integers greater than 1 can be factorized")
+
+var (
+	one = big.NewInt(1)
+	two = big.NewInt(2)
+)
+
+// IsPrime reports whether n is prime, using Miller–Rabin.
+func IsPrime(n *big.Int) bool {
+	return n.ProbablyPrime(millerRabinRounds)
+}
+
+// Factor calls emit with each prime factor of n in ascending order, repeated
+// by multiplicity. Small factors are emitted as trial division finds them;
+// the ones Pollard's rho finds are held back until n is fully factored, as
+// rho finds them in no particular order. Factor stops with ctx.Err() as soon as ctx
+// is done and with emit's error if emit fails.
+func Factor(ctx context.Context, n *big.Int, emit func(p *big.Int) error) error {
+	if n.Cmp(two) < 0 {
+		return ErrNotFactorable
+	}
+	n = new(big.Int).Set(n)
+
+	q, r := new(big.Int), new(big.Int)
+	for _, p := range smallPrimes {
+		bp := big.NewInt(p)
+		if new(big.Int).Mul(bp, bp).Cmp(n) > 0 {
+			break
+		}
+		for {
+			q.QuoRem(n, bp, r)
+			if r.Sign() != 0 {
+				break
+			}
+			if err := emit(bp); err != nil {
+				return err
+			}
+			n.Set(q)
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+
+	var large []*big.Int
+	pending := []*big.Int{n}
+	for len(pending) > 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		m := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if m.Cmp(one) == 0 {
+			continue
+		}
+		if IsPrime(m) {
+			large = append(large, m)
+			continue
+		}
+		d, err := findDivisor(ctx, m)
+		if err != nil {
+			return err
+		}
+		pending = append(pending, new(big.Int).Quo(m, d), d)
+	}
+
+	sort.Slice(large, func(i, j int) bool { return large[i].Cmp(large[j]) < 0 })
+	for _, p := range large {
+		if err := emit(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findDivisor returns a non-trivial divisor of the composite n, trying
+// Pollard's rho with successive polynomial constants until one succeeds.
+func findDivisor(ctx context.Context, n *big.Int) (*big.Int, error) {
+	if n.Bit(0) == 0 {
+		return two, nil
+	}
+	for c := int64(1); ; c++ {
+		d, err := brent(ctx, n, big.NewInt(c))
+		if err != nil {
+			return nil, err
+		}
+		if d.Cmp(n) != 0 {
+			return d, nil
+		}
+	}
+}
+
+// brent runs Brent's variant of Pollard's rho with f(x) = x² + c mod n. It
+// returns n itself when this c fails, in which case another c should be tried.
+func brent(ctx context.Context, n, c *big.Int) (*big.Int, error) {
+	const batch = 128 // gcds are batched by multiplying differences together
+
+	f := func(x *big.Int) {
+		x.Mul(x, x)
+		x.Add(x, c)
+		x.Mod(x, n)
+	}
+	diff := func(a, b *big.Int) *big.Int {
+		d := new(big.Int).Sub(a, b)
+		return d.Abs(d)
+	}
+
+	y, x, ys := big.NewInt(2), new(big.Int), new(big.Int)
+	q, g := big.NewInt(1), big.NewInt(1)
+	for r := 1; g.Cmp(one) == 0; r *= 2 {
+		x.Set(y)
+		for i := 0; i < r; i++ {
+			f(y)
+		}
+		for k := 0; k < r && g.Cmp(one) == 0; k += batch {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			ys.Set(y)
+			for i := 0; i < batch && i < r-k; i++ {
+				f(y)
+				q.Mul(q, diff(x, y))
+				q.Mod(q, n)
+			}
+			g.GCD(nil, nil, q, n)
+		}
+	}
+
+	if g.Cmp(n) == 0 {
+		// The batch overshot; step through it one gcd at a time.
+		for {
+			f(ys)
+			g.GCD(nil, nil, diff(x, ys), n)
+			if g.Cmp(one) > 0 {
+				break
+			}
+		}
+	}
+	return g, nil
+}
@@ -0,0 +1,106 @@
+package primes
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+func factors(t *testing.T, n *big.Int) []string {
+	t.Helper()
+	var got []string
+	err := Factor(context.Background(), n, func(p *big.Int) error {
+		got = append(got, p.String())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Factor(%v): %v", n, err)
+	}
+	return got
+}
+
+func TestFactor(t *testing.T) {
+	tests := []struct {
+		n    string
+		want []string
+	}{
+		{"2", []string{"2"}},
+		{"12", []string{"2", "2", "3"}},
+		{"997", []string{"997"}},
+		{"1000000", []string{"2", "2", "2", "2", "2", "2", "5", "5", "5", "5", "5", "5"}},
+		// Carmichael numbers.
+		{"561", []string{"3", "11", "17"}},
+		{"41041", []string{"7", "11", "13", "41"}},
+		// 2^64 + 1 and 2^67 - 1.
+		{"18446744073709551617", []string{"274177", "67280421310721"}},
+		{"147573952589676412927", []string{"193707721", "761838257287"}},
+		// Factors beyond trial division, which Pollard's rho finds in no
+		// particular order.
+		{"1000072001494007128009801", []string{"1000003", "1000003", "1000033", "1000033"}},
+		{"999998999167009537", []string{"999983", "999983", "1000033"}},
+		// A prime beyond 64 bits.
+		{"170141183460469231731687303715884105727", []string{"170141183460469231731687303715884105727"}},
+	}
+	for _, tt := range tests {
+		n, _ := new(big.Int).SetString(tt.n, 10)
+		got := factors(t, n)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Factor(%s) = %v, want %v", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestFactorRejectsSmallNumbers(t *testing.T) {
+	for _, n := range []int64{-5, 0, 1} {
+		err := Factor(context.Background(), big.NewInt(n), func(*big.Int) error { return nil })
+		if !errors.Is(err, ErrNotFactorable) {
+			t.Errorf("Factor(%d) = %v, want ErrNotFactorable", n, err)
+		}
+	}
+}
+
+func TestFactorCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	// The product of two 64-bit primes is out of reach of trial division.
+	n, _ := new(big.Int).SetString("340282366920938460843936948965011886881", 10)
+	err := Factor(ctx, n, func(*big.Int) error { return nil })
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Factor with a canceled context = %v, want context.Canceled", err)
+	}
+}
+
+func TestIsPrime(t *testing.T) {
+	tests := []struct {
+		n    string
+		want bool
+	}{
+		{"0", false},
+		{"1", false},
+		{"2", true},
+		{"97", true},
+		{"1000003", true},
+		// Carmichael numbers fool the Fermat test but not Miller–Rabin.
+		{"561", false},
+		{"1105", false},
+		{"1729", false},
+		{"2465", false},
+		{"2821", false},
+		{"6601", false},
+		{"8911", false},
+		{"9746347772161", false},
+		// A strong pseudoprime to bases 2, 3, 5 and 7.
+		{"3215031751", false},
+		// Mersenne primes 2^61 - 1 and 2^89 - 1.
+		{"2305843009213693951", true},
+		{"618970019642690137449562111", true},
+	}
+	for _, tt := range tests {
+		n, _ := new(big.Int).SetString(tt.n, 10)
+		if got := IsPrime(n); got != tt.want {
+			t.Errorf("IsPrime(%s) = %v, want %v", tt.n, got, tt.want)
+		}
+	}
+}
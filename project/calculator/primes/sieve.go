@@ -0,0 +1,125 @@
+package primes
+
+import (
+	"context"
+	"errors"
+	"math"
+)
+
+const (
+	// MaxRangeLimit is the largest upper bound Range accepts. It keeps the
+	// base primes up to its square root at a few megabytes.
+	MaxRangeLimit = 1 << 50
+
+	// segmentSize is how many numbers are sieved at once, which bounds the
+	// memory Range uses regardless of how wide the range is.
+	segmentSize = 1 << 16
+)
+
+// ErrRangeTooLarge is returned by Range for an upper bound above MaxRangeLimit.
+var ErrRangeTooLarge = errors.New("upper bound exceeds the supported limit")
+
+// sieve returns the primes up to and including limit, using the Sieve of
+// Eratosthenes.
+func sieve(limit int64) []int64 {
+	if limit < 2 {
+		return nil
+	}
+	composite := make([]bool, limit+1)
+	var primes []int64
+	for i := int64(2); i <= limit; i++ {
+		if composite[i] {
+			continue
+		}
+		primes = append(primes, i)
+		for j := i * i; j <= limit; j += i {
+			composite[j] = true
+		}
+	}
+	return primes
+}
+
+// Range calls emit with every prime p with from <= p <= to, in ascending
+// order, using a segmented Sieve of Eratosthenes. It stops with ctx.Err()
+// once ctx is done and with emit's error if emit fails.
+func Range(ctx context.Context, from, to int64, emit func(p int64) error) error {
+	if to > MaxRangeLimit {
+		return ErrRangeTooLarge
+	}
+	if from < 2 {
+		from = 2
+	}
+	if from > to {
+		return nil
+	}
+
+	// The base primes up to √to are themselves found segment by segment,
+	// from the handful of primes up to the fourth root of to.
+	limit := isqrt(to)
+	var base []int64
+	err := segmented(ctx, 2, limit, sieve(isqrt(limit)), func(p int64) error {
+		base = append(base, p)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return segmented(ctx, from, to, base, emit)
+}
+
+// segmented sieves [from, to] one segment at a time. base must hold every
+// prime up to √to.
+func segmented(ctx context.Context, from, to int64, base []int64, emit func(p int64) error) error {
+	composite := make([]bool, segmentSize)
+	for lo := from; lo <= to; lo += segmentSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		hi := lo + segmentSize - 1
+		if hi > to {
+			hi = to
+		}
+		segment := composite[:hi-lo+1]
+		for i := range segment {
+			segment[i] = false
+		}
+
+		for _, p := range base {
+			if p*p > hi {
+				break
+			}
+			// Multiples below p² were crossed off by smaller primes.
+			start := (lo + p - 1) / p * p
+			if start < p*p {
+				start = p * p
+			}
+			for j := start; j <= hi; j += p {
+				segment[j-lo] = true
+			}
+		}
+
+		for i, isComposite := range segment {
+			if !isComposite {
+				if err := emit(lo + int64(i)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// isqrt returns the largest r with r*r <= n.
+func isqrt(n int64) int64 {
+	if n < 0 {
+		return 0
+	}
+	r := int64(math.Sqrt(float64(n)))
+	for r*r > n {
+		r--
+	}
+	for (r+1)*(r+1) <= n {
+		r++
+	}
+	return r
+}
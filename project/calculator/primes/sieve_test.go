@@ -0,0 +1,69 @@
+package primes
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+func TestRange(t *testing.T) {
+	tests := []struct {
+		from, to int64
+	}{
+		{-10, 1},
+		{0, 100},
+		{90, 90},
+		{89, 97},
+		{100, 50},
+		// Ranges crossing segment boundaries.
+		{segmentSize - 100, segmentSize + 100},
+		{3*segmentSize - 1000, 5*segmentSize + 1000},
+		// Large bounds, whose base primes are sieved segment by segment too.
+		{1e12, 1e12 + 2000},
+		{MaxRangeLimit - 2000, MaxRangeLimit},
+	}
+	for _, tt := range tests {
+		var got []int64
+		err := Range(context.Background(), tt.from, tt.to, func(p int64) error {
+			got = append(got, p)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Range(%d, %d): %v", tt.from, tt.to, err)
+		}
+
+		var want []int64
+		for n := tt.from; n <= tt.to; n++ {
+			if n >= 2 && IsPrime(big.NewInt(n)) {
+				want = append(want, n)
+			}
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Range(%d, %d) = %v, want %v", tt.from, tt.to, got, want)
+		}
+	}
+}
+
+func TestRangeTooLarge(t *testing.T) {
+	err := Range(context.Background(), 2, MaxRangeLimit+1, func(int64) error { return nil })
+	if !errors.Is(err, ErrRangeTooLarge) {
+		t.Errorf("Range beyond MaxRangeLimit = %v, want ErrRangeTooLarge", err)
+	}
+}
+
+func TestRangeStopsOnEmitError(t *testing.T) {
+	stop := errors.New("stop")
+	var got []int64
+	err := Range(context.Background(), 0, 1000, func(p int64) error {
+		got = append(got, p)
+		if len(got) == 3 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop || !reflect.DeepEqual(got, []int64{2, 3, 5}) {
+		t.Errorf("Range = %v after %v, want stop after [2 3 5]", err, got)
+	}
+}
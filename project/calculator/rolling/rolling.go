@@ -0,0 +1,115 @@
+// Package rolling keeps aggregates over the most recent values of a stream.
+package rolling
+
+import (
+	"container/heap"
+	"errors"
+	"sort"
+	"time"
+)
+
+// ErrWindowFull is returned by Window.Add when keeping the new value would
+// take the window past its limit.
+var ErrWindowFull = errors.New("the window holds too many values")
+
+type entry struct {
+	value int64
+	seq   int64
+	at    time.Time
+}
+
+// Window tracks the maximum (or minimum) of a sliding window bounded by a
+// number of values, a time span, or both. It keeps a monotonic deque, so each
+// value is pushed and popped at most once.
+//
+// A window bounded by time alone holds every value that could still be the
+// answer, which for a steadily decreasing stream (or increasing, for the
+// minimum) is every value within the span. The limit bounds that memory.
+type Window struct {
+	size   int64         // 0 means unbounded by count
+	span   time.Duration // 0 means unbounded by time
+	limit  int           // 0 means no bound on the values held
+	prefer func(a, b int64) bool
+
+	seq   int64
+	deque []entry
+}
+
+// NewMaxWindow returns a Window reporting the maximum of the last size values
+// seen within span, holding at most limit values at once.
+func NewMaxWindow(size int64, span time.Duration, limit int) *Window {
+	return &Window{size: size, span: span, limit: limit, prefer: func(a, b int64) bool { return a > b }}
+}
+
+// NewMinWindow returns a Window reporting the minimum of the last size values
+// seen within span, holding at most limit values at once.
+func NewMinWindow(size int64, span time.Duration, limit int) *Window {
+	return &Window{size: size, span: span, limit: limit, prefer: func(a, b int64) bool { return a < b }}
+}
+
+// Add records value as seen at the given time and returns the aggregate of
+// the window it ends, or ErrWindowFull if that would take the window past its
+// limit, in which case the window is left as it was.
+func (w *Window) Add(value int64, at time.Time) (int64, error) {
+	// Expire old values first, so that only live ones count toward the
+	// limit.
+	for len(w.deque) > 0 && w.expired(w.deque[0], w.seq+1, at) {
+		w.deque = w.deque[1:]
+	}
+	// Older values that are not preferred over the new one can never be the
+	// answer again.
+	keep := len(w.deque)
+	for keep > 0 && !w.prefer(w.deque[keep-1].value, value) {
+		keep--
+	}
+	if w.limit > 0 && keep >= w.limit {
+		return 0, ErrWindowFull
+	}
+	w.seq++
+	w.deque = append(w.deque[:keep], entry{value, w.seq, at})
+	return w.deque[0].value, nil
+}
+
+// expired reports whether e has left the window ending with the value
+// numbered seq, seen at the given time.
+func (w *Window) expired(e entry, seq int64, at time.Time) bool {
+	return (w.size > 0 && e.seq <= seq-w.size) || (w.span > 0 && at.Sub(e.at) > w.span)
+}
+
+// TopK tracks the k largest values seen so far.
+type TopK struct {
+	k    int
+	heap minHeap
+}
+
+// NewTopK returns a TopK keeping k values.
+func NewTopK(k int) *TopK {
+	return &TopK{k: k}
+}
+
+// Add records value and returns the current top values in descending order.
+func (t *TopK) Add(value int64) []int64 {
+	if t.heap.Len() < t.k {
+		heap.Push(&t.heap, value)
+	} else if value > t.heap[0] {
+		t.heap[0] = value
+		heap.Fix(&t.heap, 0)
+	}
+
+	top := append([]int64(nil), t.heap...)
+	sort.Slice(top, func(i, j int) bool { return top[i] > top[j] })
+	return top
+}
+
+type minHeap []int64
+
+func (h minHeap) Len() int            { return len(h) }
+func (h minHeap) Less(i, j int) bool  { return h[i] < h[j] }
+func (h minHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *minHeap) Push(x interface{}) { *h = append(*h, x.(int64)) }
+func (h *minHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
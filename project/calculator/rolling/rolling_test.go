@@ -0,0 +1,65 @@
+package rolling
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWindow(t *testing.T) {
+	start := time.Unix(0, 0)
+	tests := []struct {
+		name   string
+		window *Window
+		values []int64
+		gaps   []time.Duration // before each value; nil means none
+		want   []int64
+	}{
+		{"max by count", NewMaxWindow(3, 0, 0), []int64{1, 3, 2, 1, 0, 5}, nil, []int64{1, 3, 3, 3, 2, 5}},
+		{"min by count", NewMinWindow(2, 0, 0), []int64{4, 2, 3, 5, 1}, nil, []int64{4, 2, 2, 3, 1}},
+		{"max by time", NewMaxWindow(0, 2*time.Second, 0), []int64{9, 1, 2, 3},
+			[]time.Duration{0, time.Second, time.Second, time.Second}, []int64{9, 9, 9, 3}},
+		{"max by both", NewMaxWindow(2, 10*time.Second, 0), []int64{9, 1, 2},
+			[]time.Duration{0, time.Second, time.Second}, []int64{9, 9, 2}},
+	}
+	for _, tt := range tests {
+		at := start
+		var got []int64
+		for i, v := range tt.values {
+			if tt.gaps != nil {
+				at = at.Add(tt.gaps[i])
+			}
+			agg, err := tt.window.Add(v, at)
+			if err != nil {
+				t.Fatalf("%s: Add(%d): %v", tt.name, v, err)
+			}
+			got = append(got, agg)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWindowLimit(t *testing.T) {
+	w := NewMaxWindow(0, time.Minute, 3)
+	at := time.Unix(0, 0)
+	for _, v := range []int64{10, 9, 8} {
+		if _, err := w.Add(v, at); err != nil {
+			t.Fatalf("Add(%d): %v", v, err)
+		}
+	}
+	// A decreasing stream keeps every value, so a fourth one is too many.
+	if _, err := w.Add(7, at); !errors.Is(err, ErrWindowFull) {
+		t.Fatalf("Add(7) = %v, want ErrWindowFull", err)
+	}
+	// A larger value replaces the ones it dominates, and expired values
+	// make room too.
+	if max, err := w.Add(9, at); err != nil || max != 10 {
+		t.Errorf("Add(9) = %d, %v, want 10", max, err)
+	}
+	if max, err := w.Add(1, at.Add(2*time.Minute)); err != nil || max != 1 {
+		t.Errorf("Add(1) after the span = %d, %v, want 1", max, err)
+	}
+}
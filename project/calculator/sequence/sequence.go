@@ -0,0 +1,203 @@
+// Package sequence generates integer sequences of unbounded size, such as
+// the Fibonacci numbers and the factorials, from any index on.
+//
+// Starting at index n costs about log n big-integer operations rather than
+// n, so that clients can resume a long sequence where they left off.
+package sequence
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// ErrTooLarge is returned when the terms at the starting index have more
+// bits than allowed.
+var ErrTooLarge = errors.New("the terms at the starting index are too large")
+
+// Generator yields consecutive terms of a sequence.
+type Generator interface {
+	// Next returns the next term. The caller may keep or modify it.
+	Next() *big.Int
+}
+
+// Fibonacci returns the Fibonacci numbers 0, 1, 1, 2, ... from index start.
+func Fibonacci(start uint64, maxBits int) (Generator, error) {
+	return LinearRecurrence(
+		[]*big.Int{big.NewInt(1), big.NewInt(1)},
+		[]*big.Int{big.NewInt(0), big.NewInt(1)},
+		start, maxBits)
+}
+
+type factorials struct {
+	n    uint64
+	term *big.Int
+}
+
+// Factorial returns the factorials 1, 1, 2, 6, ... from index start.
+func Factorial(start uint64, maxBits int) (Generator, error) {
+	if lg, _ := math.Lgamma(float64(start) + 1); lg/math.Ln2 > float64(maxBits) {
+		return nil, ErrTooLarge
+	}
+	return &factorials{n: start, term: new(big.Int).MulRange(1, int64(start))}, nil
+}
+
+func (g *factorials) Next() *big.Int {
+	term := g.term
+	g.n++
+	g.term = new(big.Int).Mul(term, new(big.Int).SetUint64(g.n))
+	return term
+}
+
+type catalans struct {
+	n    uint64
+	term *big.Int
+}
+
+// Catalan returns the Catalan numbers 1, 1, 2, 5, 14, ... from index start.
+func Catalan(start uint64, maxBits int) (Generator, error) {
+	n := float64(start)
+	lg2n, _ := math.Lgamma(2*n + 1)
+	lgn, _ := math.Lgamma(n + 1)
+	if (lg2n-2*lgn-math.Log(n+1))/math.Ln2 > float64(maxBits) {
+		return nil, ErrTooLarge
+	}
+	term := new(big.Int).Binomial(int64(2*start), int64(start))
+	term.Quo(term, new(big.Int).SetUint64(start+1))
+	return &catalans{n: start, term: term}, nil
+}
+
+// Next uses C(n+1) = C(n) * 2(2n+1) / (n+2).
+func (g *catalans) Next() *big.Int {
+	term := g.term
+	next := new(big.Int).Mul(term, new(big.Int).SetUint64(2*(2*g.n+1)))
+	g.n++
+	g.term = next.Quo(next, new(big.Int).SetUint64(g.n+1))
+	return term
+}
+
+type recurrence struct {
+	coefficients []*big.Int
+	// window holds the k terms from the next one on.
+	window []*big.Int
+}
+
+// LinearRecurrence returns the sequence with the given initial terms
+// a(0), ..., a(k-1) that continues as
+//
+//	a(n) = coefficients[0]*a(n-1) + ... + coefficients[k-1]*a(n-k)
+//
+// from index start. There must be as many initial terms as coefficients.
+// Reaching start may involve numbers larger than its terms, so a start
+// whose terms would fit can still fail with ErrTooLarge when coefficients
+// of mixed signs cancel out.
+func LinearRecurrence(coefficients, initial []*big.Int, start uint64, maxBits int) (Generator, error) {
+	k := len(coefficients)
+	if k == 0 || len(initial) != k {
+		return nil, fmt.Errorf("sequence: %d coefficients and %d initial terms", k, len(initial))
+	}
+	r := &recurrence{coefficients: coefficients, window: make([]*big.Int, k)}
+	for i, a := range initial {
+		r.window[i] = new(big.Int).Set(a)
+	}
+	if start == 0 {
+		return r, nil
+	}
+
+	// The window advances by the companion matrix M, so the window at start
+	// is M^start times the initial one.
+	m := newMatrix(k)
+	for i := 0; i < k-1; i++ {
+		m[i][i+1].SetInt64(1)
+	}
+	for i, c := range coefficients {
+		m[k-1][k-1-i].Set(c)
+	}
+	// Bounding the entries of the powers bounds the work done.
+	limit := 2*maxBits + 64
+	p, err := m.pow(start, limit)
+	if err != nil {
+		return nil, err
+	}
+	window := make([]*big.Int, k)
+	for i := range window {
+		window[i] = new(big.Int)
+		for j, a := range r.window {
+			window[i].Add(window[i], new(big.Int).Mul(p[i][j], a))
+		}
+		if window[i].BitLen() > maxBits {
+			return nil, ErrTooLarge
+		}
+	}
+	r.window = window
+	return r, nil
+}
+
+func (r *recurrence) Next() *big.Int {
+	k := len(r.window)
+	next, product := new(big.Int), new(big.Int)
+	for i, c := range r.coefficients {
+		next.Add(next, product.Mul(c, r.window[k-1-i]))
+	}
+	term := r.window[0]
+	copy(r.window, r.window[1:])
+	r.window[k-1] = next
+	return term
+}
+
+type matrix [][]*big.Int
+
+func newMatrix(k int) matrix {
+	m := make(matrix, k)
+	for i := range m {
+		m[i] = make([]*big.Int, k)
+		for j := range m[i] {
+			m[i][j] = new(big.Int)
+		}
+	}
+	return m
+}
+
+// mul returns m*o, failing if an entry has more than limit bits.
+func (m matrix) mul(o matrix, limit int) (matrix, error) {
+	k := len(m)
+	r := newMatrix(k)
+	product := new(big.Int)
+	for i := 0; i < k; i++ {
+		for j := 0; j < k; j++ {
+			for l := 0; l < k; l++ {
+				r[i][j].Add(r[i][j], product.Mul(m[i][l], o[l][j]))
+			}
+			if r[i][j].BitLen() > limit {
+				return nil, ErrTooLarge
+			}
+		}
+	}
+	return r, nil
+}
+
+// pow returns m^n for n > 0 by repeated squaring.
+func (m matrix) pow(n uint64, limit int) (matrix, error) {
+	var result matrix
+	for {
+		if n&1 == 1 {
+			if result == nil {
+				result = m
+			} else {
+				var err error
+				if result, err = result.mul(m, limit); err != nil {
+					return nil, err
+				}
+			}
+		}
+		n >>= 1
+		if n == 0 {
+			return result, nil
+		}
+		var err error
+		if m, err = m.mul(m, limit); err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package sequence
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func ints(values ...int64) []*big.Int {
+	out := make([]*big.Int, len(values))
+	for i, v := range values {
+		out[i] = big.NewInt(v)
+	}
+	return out
+}
+
+// take returns the next n terms of g as strings.
+func take(g Generator, n int) []string {
+	out := make([]string, n)
+	for i := range out {
+		out[i] = g.Next().String()
+	}
+	return out
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestTerms(t *testing.T) {
+	tests := []struct {
+		name  string
+		new   func(start uint64, maxBits int) (Generator, error)
+		start uint64
+		want  []string
+	}{
+		{"fibonacci", Fibonacci, 0, []string{"0", "1", "1", "2", "3", "5", "8", "13"}},
+		{"fibonacci", Fibonacci, 1, []string{"1", "1", "2", "3"}},
+		{"fibonacci", Fibonacci, 100, []string{"354224848179261915075", "573147844013817084101"}},
+		{"factorial", Factorial, 0, []string{"1", "1", "2", "6", "24", "120"}},
+		{"factorial", Factorial, 20, []string{"2432902008176640000", "51090942171709440000"}},
+		{"catalan", Catalan, 0, []string{"1", "1", "2", "5", "14", "42", "132", "429"}},
+		{"catalan", Catalan, 30, []string{"3814986502092304", "14544636039226909"}},
+	}
+	for _, tt := range tests {
+		g, err := tt.new(tt.start, 1<<10)
+		if err != nil {
+			t.Errorf("%s from %d: %v", tt.name, tt.start, err)
+			continue
+		}
+		if got := take(g, len(tt.want)); !equal(got, tt.want) {
+			t.Errorf("%s from %d = %v, want %v", tt.name, tt.start, got, tt.want)
+		}
+	}
+}
+
+func TestLinearRecurrence(t *testing.T) {
+	tests := []struct {
+		name                  string
+		coefficients, initial []*big.Int
+		want                  []string
+	}{
+		{"lucas", ints(1, 1), ints(2, 1), []string{"2", "1", "3", "4", "7", "11", "18"}},
+		{"pell", ints(2, 1), ints(0, 1), []string{"0", "1", "2", "5", "12", "29", "70"}},
+		{"tribonacci", ints(1, 1, 1), ints(0, 0, 1), []string{"0", "0", "1", "1", "2", "4", "7", "13", "24"}},
+		{"powers", ints(3), ints(1), []string{"1", "3", "9", "27", "81"}},
+		{"alternating", ints(-1), ints(1), []string{"1", "-1", "1", "-1"}},
+		{"periodic", ints(1, -1), ints(0, 1), []string{"0", "1", "1", "0", "-1", "-1", "0", "1"}},
+	}
+	for _, tt := range tests {
+		g, err := LinearRecurrence(tt.coefficients, tt.initial, 0, 1<<10)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got := take(g, len(tt.want)); !equal(got, tt.want) {
+			t.Errorf("%s = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestStart checks that starting at an index, which fast-forwards by powers
+// of the companion matrix, gives the terms that stepping there one by one
+// does.
+func TestStart(t *testing.T) {
+	generators := []struct {
+		name string
+		new  func(start uint64) (Generator, error)
+	}{
+		{"fibonacci", func(start uint64) (Generator, error) { return Fibonacci(start, 1<<12) }},
+		{"factorial", func(start uint64) (Generator, error) { return Factorial(start, 1<<12) }},
+		{"catalan", func(start uint64) (Generator, error) { return Catalan(start, 1<<12) }},
+		{"tribonacci", func(start uint64) (Generator, error) {
+			return LinearRecurrence(ints(1, 1, 1), ints(0, 0, 1), start, 1<<12)
+		}},
+		{"mixed signs", func(start uint64) (Generator, error) {
+			return LinearRecurrence(ints(2, -3, 1), ints(5, -1, 4), start, 1<<12)
+		}},
+	}
+	const n = 300
+	for _, gen := range generators {
+		g, err := gen.new(0)
+		if err != nil {
+			t.Fatalf("%s: %v", gen.name, err)
+		}
+		naive := take(g, n+3)
+		for _, start := range []uint64{1, 2, 3, 7, 64, 100, 255, n} {
+			g, err := gen.new(start)
+			if err != nil {
+				t.Errorf("%s from %d: %v", gen.name, start, err)
+				continue
+			}
+			if got, want := take(g, 3), naive[start:start+3]; !equal(got, want) {
+				t.Errorf("%s from %d = %v, want %v", gen.name, start, got, want)
+			}
+		}
+	}
+}
+
+func TestTooLarge(t *testing.T) {
+	tests := []struct {
+		name    string
+		new     func(start uint64, maxBits int) (Generator, error)
+		start   uint64
+		maxBits int // the fewest bits that fit
+	}{
+		// F(100) and F(101) both have 69 bits.
+		{"fibonacci", Fibonacci, 100, 69},
+		// 20! has 62 bits.
+		{"factorial", Factorial, 20, 62},
+		// C(30) has 52 bits.
+		{"catalan", Catalan, 30, 52},
+	}
+	for _, tt := range tests {
+		if _, err := tt.new(tt.start, tt.maxBits); err != nil {
+			t.Errorf("%s from %d with %d bits: %v", tt.name, tt.start, tt.maxBits, err)
+		}
+		if _, err := tt.new(tt.start, tt.maxBits-1); !errors.Is(err, ErrTooLarge) {
+			t.Errorf("%s from %d with %d bits = %v, want ErrTooLarge", tt.name, tt.start, tt.maxBits-1, err)
+		}
+		// Far-off starts fail quickly rather than computing huge terms.
+		if _, err := tt.new(1<<62, 1<<18); !errors.Is(err, ErrTooLarge) {
+			t.Errorf("%s from 2^62 = %v, want ErrTooLarge", tt.name, err)
+		}
+	}
+}
+
+func TestLinearRecurrenceErrors(t *testing.T) {
+	tests := []struct {
+		name                  string
+		coefficients, initial []*big.Int
+	}{
+		{"no coefficients", nil, nil},
+		{"too few initial terms", ints(1, 1), ints(0)},
+		{"too many initial terms", ints(1), ints(0, 1)},
+	}
+	for _, tt := range tests {
+		if _, err := LinearRecurrence(tt.coefficients, tt.initial, 0, 64); err == nil {
+			t.Errorf("%s: LinearRecurrence succeeded", tt.name)
+		}
+	}
+}
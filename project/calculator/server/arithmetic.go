@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+
+	"github.com/grpc-project02/project/calculator/calculatorpb"
+	"github.com/grpc-project02/project/calculator/decimal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// defaultDivisionScale is used when a DecimalArithmetic division does
+	// not ask for a scale.
+	defaultDivisionScale = 20
+
+	// maxDivisionScale bounds the work and response size of a division.
+	maxDivisionScale = 1000
+
+	// maxNumberLength bounds the length of the numbers clients send as
+	// strings. Parsing decimal strings takes more than linear time, so it
+	// is checked before parsing.
+	maxNumberLength = 4096
+)
+
+// checkLength rejects s if it is longer than max characters.
+func checkLength(field, s string, max int) error {
+	if len(s) > max {
+		return badRequest(field, fmt.Sprintf("must be at most %d characters long, got %d", max, len(s)))
+	}
+	return nil
+}
+
+// parseBigInt reads the decimal integer held by field.
+func parseBigInt(field, s string) (*big.Int, error) {
+	if err := checkLength(field, s, maxNumberLength); err != nil {
+		return nil, err
+	}
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return nil, badRequest(field, fmt.Sprintf("%q is not a decimal integer", s))
+	}
+	return n, nil
+}
+
+func (*server) BigIntegerArithmetic(ctx context.Context, request *calculatorpb.BigIntegerArithmeticRequest) (*calculatorpb.BigIntegerArithmeticResponse, error) {
+	log.Printf("Received BigIntegerArithmetic RPC: %v", request)
+	x, err := parseBigInt("first_number", request.GetFirstNumber())
+	if err != nil {
+		return nil, err
+	}
+	y, err := parseBigInt("second_number", request.GetSecondNumber())
+	if err != nil {
+		return nil, err
+	}
+
+	res := &calculatorpb.BigIntegerArithmeticResponse{}
+	z := new(big.Int)
+	switch request.GetOperation() {
+	case calculatorpb.ArithmeticOperation_ADD:
+		z.Add(x, y)
+	case calculatorpb.ArithmeticOperation_SUBTRACT:
+		z.Sub(x, y)
+	case calculatorpb.ArithmeticOperation_MULTIPLY:
+		z.Mul(x, y)
+	case calculatorpb.ArithmeticOperation_DIVIDE:
+		if y.Sign() == 0 {
+			return nil, badRequest("second_number", "division by zero")
+		}
+		r := new(big.Int)
+		z.QuoRem(x, y, r)
+		res.Remainder = r.String()
+	default:
+		return nil, badRequest("operation", fmt.Sprintf("unknown operation %v", request.GetOperation()))
+	}
+	res.Result = z.String()
+	return res, nil
+}
+
+func (*server) DecimalArithmetic(ctx context.Context, request *calculatorpb.DecimalArithmeticRequest) (*calculatorpb.DecimalArithmeticResponse, error) {
+	log.Printf("Received DecimalArithmetic RPC: %v", request)
+	if err := checkLength("first_number", request.GetFirstNumber(), maxNumberLength); err != nil {
+		return nil, err
+	}
+	if err := checkLength("second_number", request.GetSecondNumber(), maxNumberLength); err != nil {
+		return nil, err
+	}
+	x, err := decimal.Parse(request.GetFirstNumber())
+	if err != nil {
+		return nil, badRequest("first_number", err.Error())
+	}
+	y, err := decimal.Parse(request.GetSecondNumber())
+	if err != nil {
+		return nil, badRequest("second_number", err.Error())
+	}
+
+	var z *decimal.Decimal
+	switch request.GetOperation() {
+	case calculatorpb.ArithmeticOperation_ADD:
+		z = decimal.Add(x, y)
+	case calculatorpb.ArithmeticOperation_SUBTRACT:
+		z = decimal.Sub(x, y)
+	case calculatorpb.ArithmeticOperation_MULTIPLY:
+		z = decimal.Mul(x, y)
+	case calculatorpb.ArithmeticOperation_DIVIDE:
+		scale := uint32(defaultDivisionScale)
+		if request.DivisionScale != nil {
+			scale = request.GetDivisionScale()
+		}
+		if scale > maxDivisionScale {
+			return nil, badRequest("division_scale", fmt.Sprintf("must be at most %d", maxDivisionScale))
+		}
+		if z, err = decimal.Quo(x, y, int32(scale)); err != nil {
+			return nil, badRequest("second_number", err.Error())
+		}
+	default:
+		return nil, badRequest("operation", fmt.Sprintf("unknown operation %v", request.GetOperation()))
+	}
+	return &calculatorpb.DecimalArithmeticResponse{
+		Result: z.String(),
+	}, nil
+}
+
+// addInt64 returns a + b, or an OutOfRange error if the sum overflows.
+func addInt64(a, b int64) (int64, error) {
+	sum := a + b
+	if (b > 0 && sum < a) || (b < 0 && sum > a) {
+		return 0, status.Errorf(codes.OutOfRange,
+			"%d + %d overflows int64; use BigIntegerArithmetic for larger numbers", a, b)
+	}
+	return sum, nil
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/grpc-project02/project/calculator/cache"
+	"github.com/grpc-project02/project/calculator/calculatorpb"
+)
+
+func (s *server) GetCacheStats(ctx context.Context, request *calculatorpb.GetCacheStatsRequest) (*calculatorpb.GetCacheStatsResponse, error) {
+	log.Println("Received GetCacheStats RPC")
+	return &calculatorpb.GetCacheStatsResponse{
+		Caches: []*calculatorpb.CacheStats{
+			cacheStats("PrimeNumberDecomposition", s.factors),
+			cacheStats("SquareRoot", s.roots),
+		},
+	}, nil
+}
+
+func cacheStats(name string, c *cache.Cache) *calculatorpb.CacheStats {
+	stats := c.Stats()
+	return &calculatorpb.CacheStats{
+		Name:      name,
+		Hits:      stats.Hits,
+		Misses:    stats.Misses,
+		Coalesced: stats.Coalesced,
+		Evictions: stats.Evictions,
+		Entries:   uint32(stats.Entries),
+		Capacity:  uint32(stats.Capacity),
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"sync"
+
+	"github.com/grpc-project02/project/calculator/calculatorpb"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// calculateWorkers bounds how many operations of one Calculate call run
+	// at once. Further requests wait to be received until a worker is free.
+	calculateWorkers = 8
+
+	// maxCalculateIDLength bounds the ids clients choose.
+	maxCalculateIDLength = 128
+)
+
+func (s *server) Calculate(stream calculatorpb.CalculatorService_CalculateServer) error {
+	log.Println("Received Calculate RPC")
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		inFlight = make(map[string]bool)
+	)
+	jobs := make(chan *calculatorpb.CalculateRequest)
+	results := make(chan *calculatorpb.CalculateResponse)
+
+	var workers sync.WaitGroup
+	for i := 0; i < calculateWorkers; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for request := range jobs {
+				results <- s.calculate(ctx, request)
+			}
+		}()
+	}
+
+	// Only one goroutine may send on a stream. After a failed send the
+	// remaining results are drained so that no worker blocks.
+	sendErr := make(chan error, 1)
+	go func() {
+		var err error
+		for response := range results {
+			mu.Lock()
+			delete(inFlight, response.GetId())
+			mu.Unlock()
+			if err == nil {
+				if err = stream.Send(response); err != nil {
+					log.Printf("Error while sending client stream: %v", err)
+				}
+			}
+		}
+		sendErr <- err
+	}()
+
+	recvErr := func() error {
+		for i := 0; ; i++ {
+			recv, err := stream.Recv()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				log.Printf("Error while reading client stream: %v", err)
+				return err
+			}
+
+			field := fmt.Sprintf("messages[%d].id", i)
+			id := recv.GetId()
+			if id == "" {
+				return badRequest(field, "an id is required")
+			}
+			if len(id) > maxCalculateIDLength {
+				return badRequest(field, fmt.Sprintf("must be at most %d bytes", maxCalculateIDLength))
+			}
+			mu.Lock()
+			duplicate := inFlight[id]
+			inFlight[id] = true
+			mu.Unlock()
+			if duplicate {
+				return badRequest(field, fmt.Sprintf("%q is already in flight", id))
+			}
+
+			jobs <- recv
+		}
+	}()
+
+	close(jobs)
+	if recvErr != nil {
+		// The call is failing, so the operations still running are moot.
+		cancel()
+	}
+	workers.Wait()
+	close(results)
+	if err := <-sendErr; err != nil && recvErr == nil {
+		return err
+	}
+	return recvErr
+}
+
+// calculate runs a single operation of a Calculate call. Errors are reported
+// in the response rather than ending the stream.
+func (s *server) calculate(ctx context.Context, request *calculatorpb.CalculateRequest) *calculatorpb.CalculateResponse {
+	response := &calculatorpb.CalculateResponse{Id: request.GetId()}
+	var err error
+	switch op := request.GetOperation().(type) {
+	case *calculatorpb.CalculateRequest_Sum:
+		var result *calculatorpb.SumResponse
+		if result, err = s.Sum(ctx, op.Sum); err == nil {
+			response.Result = &calculatorpb.CalculateResponse_Sum{Sum: result}
+		}
+	case *calculatorpb.CalculateRequest_SquareRoot:
+		var result *calculatorpb.SquareRootResponse
+		if result, err = s.SquareRoot(ctx, op.SquareRoot); err == nil {
+			response.Result = &calculatorpb.CalculateResponse_SquareRoot{SquareRoot: result}
+		}
+	case *calculatorpb.CalculateRequest_Factorize:
+		var result *calculatorpb.Factorization
+		if result, err = s.factorize(ctx, op.Factorize); err == nil {
+			response.Result = &calculatorpb.CalculateResponse_Factorize{Factorize: result}
+		}
+	case *calculatorpb.CalculateRequest_Evaluate:
+		var result *calculatorpb.EvaluateResponse
+		if result, err = s.Evaluate(ctx, op.Evaluate); err == nil {
+			response.Result = &calculatorpb.CalculateResponse_Evaluate{Evaluate: result}
+		}
+	default:
+		err = badRequest("operation", "an operation is required")
+	}
+
+	if err != nil {
+		response.Result = &calculatorpb.CalculateResponse_Error{Error: calculateError(err)}
+	}
+	return response
+}
+
+// calculateError converts the error of an operation into a CalculateError.
+func calculateError(err error) *calculatorpb.CalculateError {
+	st := status.Convert(streamError(err)).Proto()
+	return &calculatorpb.CalculateError{
+		Code:    st.GetCode(),
+		Message: st.GetMessage(),
+		Details: st.GetDetails(),
+	}
+}
+
+// factorize collects the prime factors PrimeNumberDecomposition would
+// stream, under the same limits and sharing its cache.
+func (s *server) factorize(ctx context.Context, request *calculatorpb.PrimeNumberDecompositionRequest) (*calculatorpb.Factorization, error) {
+	number, err := decompositionInput(request)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &calculatorpb.Factorization{}
+	err = s.factors.Stream(ctx, number.String(), computeFactors(number), func(v interface{}) error {
+		result.Primes = append(result.Primes, v.(*big.Int).String())
+		return nil
+	})
+	if err != nil {
+		return nil, streamError(err)
+	}
+	return result, nil
+}
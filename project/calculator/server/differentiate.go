@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/grpc-project02/project/calculator/calculatorpb"
+	"github.com/grpc-project02/project/calculator/expr"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// maxDifferentiateLength bounds the expressions Differentiate accepts,
+	// as a derivative can be quadratically larger than its expression.
+	maxDifferentiateLength = 4096
+
+	// maxDerivativeNodes bounds the size of the derivative returned.
+	maxDerivativeNodes = 100000
+)
+
+// expressionProto converts n to its message form. It fails once more than
+// *budget nodes have been converted.
+func expressionProto(n expr.Node, budget *int) (*calculatorpb.ExpressionNode, error) {
+	if *budget--; *budget < 0 {
+		return nil, status.Errorf(codes.ResourceExhausted, "the derivative has more than %d nodes", maxDerivativeNodes)
+	}
+	switch n := n.(type) {
+	case *expr.Number:
+		return &calculatorpb.ExpressionNode{Node: &calculatorpb.ExpressionNode_Number{Number: n.Value}}, nil
+	case *expr.Variable:
+		return &calculatorpb.ExpressionNode{Node: &calculatorpb.ExpressionNode_Variable{Variable: n.Name}}, nil
+	case *expr.Unary:
+		operand, err := expressionProto(n.X, budget)
+		if err != nil {
+			return nil, err
+		}
+		return &calculatorpb.ExpressionNode{Node: &calculatorpb.ExpressionNode_Unary{Unary: &calculatorpb.UnaryExpression{
+			Op:      string(n.Op),
+			Operand: operand,
+		}}}, nil
+	case *expr.Binary:
+		left, err := expressionProto(n.X, budget)
+		if err != nil {
+			return nil, err
+		}
+		right, err := expressionProto(n.Y, budget)
+		if err != nil {
+			return nil, err
+		}
+		return &calculatorpb.ExpressionNode{Node: &calculatorpb.ExpressionNode_Binary{Binary: &calculatorpb.BinaryExpression{
+			Op:    string(n.Op),
+			Left:  left,
+			Right: right,
+		}}}, nil
+	case *expr.Call:
+		call := &calculatorpb.CallExpression{Function: n.Func}
+		for _, arg := range n.Args {
+			a, err := expressionProto(arg, budget)
+			if err != nil {
+				return nil, err
+			}
+			call.Args = append(call.Args, a)
+		}
+		return &calculatorpb.ExpressionNode{Node: &calculatorpb.ExpressionNode_Call{Call: call}}, nil
+	}
+	return nil, status.Errorf(codes.Internal, "unknown expression node %T", n)
+}
+
+func (*server) Differentiate(ctx context.Context, request *calculatorpb.DifferentiateRequest) (*calculatorpb.DifferentiateResponse, error) {
+	log.Printf("Received Differentiate RPC: %v", request)
+	if len(request.GetExpression()) > maxDifferentiateLength {
+		return nil, badRequest("expression", fmt.Sprintf("must be at most %d bytes long", maxDifferentiateLength))
+	}
+	variable, err := variableName("variable", request.GetVariable())
+	if err != nil {
+		return nil, err
+	}
+	tree, err := expr.Parse(request.GetExpression())
+	if err != nil {
+		return nil, expressionError("expression", err)
+	}
+	derivative, err := expr.Derive(tree, variable)
+	if err != nil {
+		return nil, expressionError("expression", err)
+	}
+
+	budget := maxDerivativeNodes
+	node, err := expressionProto(derivative, &budget)
+	if err != nil {
+		return nil, err
+	}
+	return &calculatorpb.DifferentiateResponse{
+		Derivative: expr.Format(derivative),
+		Tree:       node,
+	}, nil
+}
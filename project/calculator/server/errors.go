@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/grpc-project02/project/calculator/expr"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// badRequest builds an InvalidArgument status whose BadRequest detail names
+// the offending request field.
+func badRequest(field, description string) error {
+	st := status.New(codes.InvalidArgument, field+": "+description)
+	detailed, err := st.WithDetails(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{{
+			Field:       field,
+			Description: description,
+		}},
+	})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// streamError converts the error that ended a streaming handler into a
+// status, mapping a finished context to Canceled or DeadlineExceeded.
+func streamError(err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+	return err
+}
+
+// expressionError reports an error in the expression held by field. Errors
+// from the expr package carry the column they occur at.
+func expressionError(field string, err error) error {
+	var exprErr *expr.Error
+	if errors.As(err, &exprErr) {
+		return badRequest(field, exprErr.Error())
+	}
+	return status.Errorf(codes.Internal, "evaluating %s: %v", field, err)
+}
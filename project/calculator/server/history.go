@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/grpc-project02/project/calculator/calculatorpb"
+	"github.com/grpc-project02/project/calculator/history"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// calculatorServicePrefix starts the full method name of every
+	// CalculatorService call.
+	calculatorServicePrefix = "/calculator.CalculatorService/"
+
+	historyMaxEntries = 100000
+	historyMaxAge     = 30 * 24 * time.Hour
+	historyMaxBytes   = 64 << 20
+
+	// maxRecordedMessages bounds how many messages of each direction of a
+	// stream are recorded.
+	maxRecordedMessages = 16
+)
+
+// historyRecorder intercepts CalculatorService calls and records them.
+type historyRecorder struct {
+	store history.Store
+}
+
+func (h *historyRecorder) unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	method, ok := recordedMethod(info.FullMethod)
+	if !ok {
+		return handler(ctx, req)
+	}
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	response := ""
+	if err == nil {
+		response = messageJSON(resp)
+	}
+	h.record(ctx, method, start, messageJSON(req), response, err)
+	return resp, err
+}
+
+func (h *historyRecorder) streamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	method, ok := recordedMethod(info.FullMethod)
+	if !ok {
+		return handler(srv, ss)
+	}
+	start := time.Now()
+	rs := &recordingStream{ServerStream: ss}
+	err := handler(srv, rs)
+	h.record(ss.Context(), method, start, jsonArray(rs.received), jsonArray(rs.sent), err)
+	return err
+}
+
+func (h *historyRecorder) record(ctx context.Context, method string, start time.Time, request, response string, err error) {
+	st := status.Convert(err)
+	entry := history.Entry{
+		Method:   method,
+		Time:     start,
+		Duration: time.Since(start),
+		Caller:   clientIdentity(ctx),
+		Session:  sessionID(ctx),
+		Request:  request,
+		Response: response,
+		Code:     uint32(st.Code()),
+	}
+	if err != nil {
+		entry.Error = st.Message()
+	}
+	if err := h.store.Record(entry); err != nil {
+		log.Printf("Failed to record %v call in history: %v", method, err)
+	}
+}
+
+// recordedMethod returns the short name of a CalculatorService method and
+// whether calls to it are recorded.
+func recordedMethod(fullMethod string) (string, bool) {
+	if !strings.HasPrefix(fullMethod, calculatorServicePrefix) {
+		return "", false
+	}
+	method := strings.TrimPrefix(fullMethod, calculatorServicePrefix)
+	return method, method != "ListHistory"
+}
+
+// recordingStream keeps the first messages received and sent on a stream.
+type recordingStream struct {
+	grpc.ServerStream
+	received, sent           []string
+	receivedBytes, sentBytes int
+}
+
+func (s *recordingStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.received, s.receivedBytes = recordMessage(s.received, s.receivedBytes, m)
+	}
+	return err
+}
+
+func (s *recordingStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.sent, s.sentBytes = recordMessage(s.sent, s.sentBytes, m)
+	}
+	return err
+}
+
+// recordMessage appends m to messages, which hold size bytes, unless enough
+// has been recorded already: the store truncates anything past
+// history.MaxFieldLength.
+func recordMessage(messages []string, size int, m interface{}) ([]string, int) {
+	if len(messages) >= maxRecordedMessages || size > history.MaxFieldLength {
+		return messages, size
+	}
+	msg := messageJSON(m)
+	return append(messages, msg), size + len(msg) + 1
+}
+
+func messageJSON(m interface{}) string {
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return ""
+	}
+	b, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(msg)
+	if err != nil {
+		return fmt.Sprintf("%q", err.Error())
+	}
+	return string(b)
+}
+
+func jsonArray(messages []string) string {
+	return "[" + strings.Join(messages, ",") + "]"
+}
+
+func (s *server) ListHistory(request *calculatorpb.ListHistoryRequest, stream calculatorpb.CalculatorService_ListHistoryServer) error {
+	log.Printf("Received ListHistory RPC: %v", request)
+	filter := history.Filter{Method: request.GetMethod()}
+	if t := request.GetSince(); t != nil {
+		if err := t.CheckValid(); err != nil {
+			return badRequest("since", err.Error())
+		}
+		filter.Since = t.AsTime()
+	}
+	if t := request.GetUntil(); t != nil {
+		if err := t.CheckValid(); err != nil {
+			return badRequest("until", err.Error())
+		}
+		filter.Until = t.AsTime()
+	}
+	if !filter.Since.IsZero() && !filter.Until.IsZero() && !filter.Since.Before(filter.Until) {
+		return badRequest("until", "must be after since")
+	}
+
+	// Clients see only their own calls, unless they audit.
+	caller := clientIdentity(stream.Context())
+	if s.auditor == nil || !s.auditor(caller) {
+		if caller == "" {
+			return status.Error(codes.PermissionDenied, "the caller is unknown")
+		}
+		filter.Caller = caller
+	}
+
+	entries, err := s.history.Query(filter)
+	if err != nil {
+		return status.Errorf(codes.Internal, "reading history: %v", err)
+	}
+	for _, e := range entries {
+		// Session ids let their holder act in the session, so they are only
+		// shown to the client that owns them.
+		sessionID := ""
+		if e.Caller == caller {
+			sessionID = e.Session
+		}
+		err := stream.Send(&calculatorpb.ListHistoryResponse{Entry: &calculatorpb.HistoryEntry{
+			Method:    e.Method,
+			Time:      timestamppb.New(e.Time),
+			Duration:  durationpb.New(e.Duration),
+			Caller:    e.Caller,
+			SessionId: sessionID,
+			Request:   e.Request,
+			Response:  e.Response,
+			Code:      int32(e.Code),
+			Error:     e.Error,
+			Truncated: e.Truncated,
+		}})
+		if err != nil {
+			log.Printf("Error while sending client stream: %v", err)
+			return err
+		}
+	}
+	return nil
+}
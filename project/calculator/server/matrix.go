@@ -0,0 +1,315 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+
+	"github.com/grpc-project02/project/calculator/calculatorpb"
+	"github.com/grpc-project02/project/calculator/matrix"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// maxMatrixElements bounds each operand and each product, 32 MiB of
+	// float64s.
+	maxMatrixElements = matrix.MaxElements
+
+	// maxMatrixDimension bounds rows and columns separately, so a
+	// 1×4M vector is fine but the row count cannot overflow anything.
+	maxMatrixDimension = 1 << 22
+)
+
+type matrixServer struct {
+}
+
+// checkShape validates the dimensions given for an operand.
+func checkShape(field string, rows, cols uint32) error {
+	if rows > maxMatrixDimension || cols > maxMatrixDimension || uint64(rows)*uint64(cols) > maxMatrixElements {
+		return badRequest(field, fmt.Sprintf("%d×%d matrix exceeds the limit of %d elements", rows, cols, maxMatrixElements))
+	}
+	return nil
+}
+
+// toDense converts the matrix held by field, checking that its values match
+// its dimensions.
+func toDense(field string, m *calculatorpb.Matrix) (*matrix.Dense, error) {
+	if m == nil {
+		return nil, badRequest(field, "matrix is required")
+	}
+	if err := checkShape(field, m.GetRows(), m.GetCols()); err != nil {
+		return nil, err
+	}
+	want := int(m.GetRows()) * int(m.GetCols())
+	if len(m.GetValues()) != want {
+		return nil, badRequest(field+".values", fmt.Sprintf("a %d×%d matrix needs %d values, got %d", m.GetRows(), m.GetCols(), want, len(m.GetValues())))
+	}
+	for i, v := range m.GetValues() {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, badRequest(fmt.Sprintf("%s.values[%d]", field, i), fmt.Sprintf("%v is not a finite number", v))
+		}
+	}
+	return &matrix.Dense{Rows: int(m.GetRows()), Cols: int(m.GetCols()), Data: m.GetValues()}, nil
+}
+
+func fromDense(d *matrix.Dense) *calculatorpb.Matrix {
+	return &calculatorpb.Matrix{
+		Rows:   uint32(d.Rows),
+		Cols:   uint32(d.Cols),
+		Values: d.Data,
+	}
+}
+
+// matrixError converts an error from the matrix package into a status.
+func matrixError(err error) error {
+	var shapeErr *matrix.ShapeError
+	switch {
+	case errors.As(err, &shapeErr):
+		return badRequest(shapeErr.Operand, shapeErr.Error())
+	case errors.Is(err, matrix.ErrSingular):
+		return badRequest("a", err.Error())
+	case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	}
+	return status.Errorf(codes.Internal, "matrix operation failed: %v", err)
+}
+
+// operands converts the a and b operands of a request.
+func operands(a, b *calculatorpb.Matrix) (*matrix.Dense, *matrix.Dense, error) {
+	da, err := toDense("a", a)
+	if err != nil {
+		return nil, nil, err
+	}
+	db, err := toDense("b", b)
+	if err != nil {
+		return nil, nil, err
+	}
+	return da, db, nil
+}
+
+func (*matrixServer) Multiply(ctx context.Context, request *calculatorpb.MultiplyRequest) (*calculatorpb.MatrixResponse, error) {
+	log.Println("Received Multiply RPC")
+	a, b, err := operands(request.GetA(), request.GetB())
+	if err != nil {
+		return nil, err
+	}
+	// Check the product before multiplying, as two vectors within the
+	// operand limit can have an outer product far beyond it.
+	if err := matrix.CheckProduct(a.Rows, b.Cols); err != nil {
+		return nil, matrixError(err)
+	}
+	c, err := matrix.Mul(ctx, a, b)
+	if err != nil {
+		return nil, matrixError(err)
+	}
+	return &calculatorpb.MatrixResponse{Matrix: fromDense(c)}, nil
+}
+
+func (*matrixServer) Transpose(ctx context.Context, request *calculatorpb.TransposeRequest) (*calculatorpb.MatrixResponse, error) {
+	log.Println("Received Transpose RPC")
+	a, err := toDense("a", request.GetA())
+	if err != nil {
+		return nil, err
+	}
+	return &calculatorpb.MatrixResponse{Matrix: fromDense(matrix.Transpose(a))}, nil
+}
+
+func (*matrixServer) Determinant(ctx context.Context, request *calculatorpb.DeterminantRequest) (*calculatorpb.DeterminantResponse, error) {
+	log.Println("Received Determinant RPC")
+	a, err := toDense("a", request.GetA())
+	if err != nil {
+		return nil, err
+	}
+	det, err := matrix.Det(ctx, a)
+	if err != nil {
+		return nil, matrixError(err)
+	}
+	return &calculatorpb.DeterminantResponse{Determinant: det}, nil
+}
+
+func (*matrixServer) Inverse(ctx context.Context, request *calculatorpb.InverseRequest) (*calculatorpb.MatrixResponse, error) {
+	log.Println("Received Inverse RPC")
+	a, err := toDense("a", request.GetA())
+	if err != nil {
+		return nil, err
+	}
+	inv, err := matrix.Inverse(ctx, a)
+	if err != nil {
+		return nil, matrixError(err)
+	}
+	return &calculatorpb.MatrixResponse{Matrix: fromDense(inv)}, nil
+}
+
+func (*matrixServer) Solve(ctx context.Context, request *calculatorpb.SolveRequest) (*calculatorpb.MatrixResponse, error) {
+	log.Println("Received Solve RPC")
+	a, b, err := operands(request.GetA(), request.GetB())
+	if err != nil {
+		return nil, err
+	}
+	x, err := matrix.Solve(ctx, a, b)
+	if err != nil {
+		return nil, matrixError(err)
+	}
+	return &calculatorpb.MatrixResponse{Matrix: fromDense(x)}, nil
+}
+
+// streamedOperand collects the rows of one operand of StreamOperation.
+type streamedOperand struct {
+	field    string
+	m        *matrix.Dense
+	received []bool
+	missing  int
+}
+
+func newStreamedOperand(field string, shape *calculatorpb.Shape) (*streamedOperand, error) {
+	if err := checkShape(field, shape.GetRows(), shape.GetCols()); err != nil {
+		return nil, err
+	}
+	rows, cols := int(shape.GetRows()), int(shape.GetCols())
+	return &streamedOperand{
+		field:    field,
+		m:        matrix.New(rows, cols),
+		received: make([]bool, rows),
+		missing:  rows,
+	}, nil
+}
+
+func (o *streamedOperand) add(index int, row *calculatorpb.OperandRow) error {
+	field := fmt.Sprintf("messages[%d].row", index)
+	i := int(row.GetRow())
+	if i >= o.m.Rows {
+		return badRequest(field+".row", fmt.Sprintf("%s has %d rows, got row %d", o.field, o.m.Rows, i))
+	}
+	if o.received[i] {
+		return badRequest(field+".row", fmt.Sprintf("row %d of %s was already sent", i, o.field))
+	}
+	if len(row.GetValues()) != o.m.Cols {
+		return badRequest(field+".values", fmt.Sprintf("%s has %d columns, got %d values", o.field, o.m.Cols, len(row.GetValues())))
+	}
+	for j, v := range row.GetValues() {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return badRequest(fmt.Sprintf("%s.values[%d]", field, j), fmt.Sprintf("%v is not a finite number", v))
+		}
+	}
+	copy(o.m.Row(i), row.GetValues())
+	o.received[i] = true
+	o.missing--
+	return nil
+}
+
+func (*matrixServer) StreamOperation(stream calculatorpb.MatrixService_StreamOperationServer) error {
+	log.Println("Received StreamOperation RPC")
+
+	first, err := stream.Recv()
+	if err == io.EOF {
+		return badRequest("messages[0]", "a header is required")
+	}
+	if err != nil {
+		return err
+	}
+	header := first.GetHeader()
+	if header == nil {
+		return badRequest("messages[0]", "the first message must be a header")
+	}
+	op := header.GetOperation()
+
+	a, err := newStreamedOperand("a", header.GetA())
+	if err != nil {
+		return err
+	}
+	var b *streamedOperand
+	if op == calculatorpb.MatrixStreamHeader_MULTIPLY || op == calculatorpb.MatrixStreamHeader_SOLVE {
+		if b, err = newStreamedOperand("b", header.GetB()); err != nil {
+			return err
+		}
+	}
+	if op == calculatorpb.MatrixStreamHeader_MULTIPLY {
+		// Reject an oversized product before the client streams any rows.
+		if err := matrix.CheckProduct(a.m.Rows, b.m.Cols); err != nil {
+			return matrixError(err)
+		}
+	}
+
+	for i := 1; ; i++ {
+		recv, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Printf("Error while reading client stream: %v", err)
+			return err
+		}
+		row := recv.GetRow()
+		if row == nil {
+			return badRequest(fmt.Sprintf("messages[%d]", i), "only the first message may be a header")
+		}
+		target := a
+		if row.GetOperand() == calculatorpb.OperandRow_B {
+			if b == nil {
+				return badRequest(fmt.Sprintf("messages[%d].row.operand", i), fmt.Sprintf("%v takes no b operand", op))
+			}
+			target = b
+		}
+		if err := target.add(i, row); err != nil {
+			return err
+		}
+	}
+	for _, o := range []*streamedOperand{a, b} {
+		if o != nil && o.missing > 0 {
+			return badRequest(o.field, fmt.Sprintf("%d of %d rows were not sent", o.missing, o.m.Rows))
+		}
+	}
+
+	ctx := stream.Context()
+	var result *matrix.Dense
+	switch op {
+	case calculatorpb.MatrixStreamHeader_MULTIPLY:
+		result, err = matrix.Mul(ctx, a.m, b.m)
+	case calculatorpb.MatrixStreamHeader_TRANSPOSE:
+		result = matrix.Transpose(a.m)
+	case calculatorpb.MatrixStreamHeader_INVERSE:
+		result, err = matrix.Inverse(ctx, a.m)
+	case calculatorpb.MatrixStreamHeader_SOLVE:
+		result, err = matrix.Solve(ctx, a.m, b.m)
+	case calculatorpb.MatrixStreamHeader_DETERMINANT:
+		det, err := matrix.Det(ctx, a.m)
+		if err != nil {
+			return matrixError(err)
+		}
+		return stream.Send(&calculatorpb.StreamMatrixResponse{
+			Payload: &calculatorpb.StreamMatrixResponse_Determinant{Determinant: det},
+		})
+	default:
+		return badRequest("messages[0].header.operation", fmt.Sprintf("unknown operation %v", op))
+	}
+	if err != nil {
+		return matrixError(err)
+	}
+
+	err = stream.Send(&calculatorpb.StreamMatrixResponse{
+		Payload: &calculatorpb.StreamMatrixResponse_Shape{Shape: &calculatorpb.Shape{
+			Rows: uint32(result.Rows),
+			Cols: uint32(result.Cols),
+		}},
+	})
+	if err != nil {
+		return err
+	}
+	for i := 0; i < result.Rows; i++ {
+		err := stream.Send(&calculatorpb.StreamMatrixResponse{
+			Payload: &calculatorpb.StreamMatrixResponse_Row{Row: &calculatorpb.MatrixRow{
+				Row:    uint32(i),
+				Values: result.Row(i),
+			}},
+		})
+		if err != nil {
+			log.Printf("Error while sending client stream: %v", err)
+			return err
+		}
+	}
+	return nil
+}
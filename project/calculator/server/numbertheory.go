@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+
+	"github.com/grpc-project02/project/calculator/calculatorpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// maxNumberTheoryBits bounds the inputs of the number-theory RPCs.
+	maxNumberTheoryBits = 4096
+
+	// maxLcmBits bounds the LCM of a stream, which grows with every number.
+	maxLcmBits = 1 << 16
+)
+
+// parseBoundedInt parses the number held by field, rejecting numbers longer
+// than maxNumberTheoryBits.
+func parseBoundedInt(field, s string) (*big.Int, error) {
+	return parseIntBits(field, s, maxNumberTheoryBits)
+}
+
+// parseIntBits parses the number held by field, rejecting numbers longer than
+// bits. Strings with more digits than such a number are rejected before
+// parsing.
+func parseIntBits(field, s string, bits int) (*big.Int, error) {
+	// log10(2) < 0.30103; one more character for rounding and one for a sign.
+	if err := checkLength(field, s, bits*30103/100000+2); err != nil {
+		return nil, err
+	}
+	n, err := parseBigInt(field, s)
+	if err != nil {
+		return nil, err
+	}
+	if n.BitLen() > bits {
+		return nil, badRequest(field, fmt.Sprintf("number has %d bits; at most %d are supported", n.BitLen(), bits))
+	}
+	return n, nil
+}
+
+// parseModulus parses the modulus held by field.
+func parseModulus(field, s string) (*big.Int, error) {
+	m, err := parseBoundedInt(field, s)
+	if err != nil {
+		return nil, err
+	}
+	if m.Sign() <= 0 {
+		return nil, badRequest(field, fmt.Sprintf("modulus must be positive, got %v", m))
+	}
+	return m, nil
+}
+
+// foldNumbers reads a stream of numbers and folds them with combine,
+// starting from the first one.
+func foldNumbers(recv func() (string, error), combine func(acc, n *big.Int) error) (*big.Int, error) {
+	var acc *big.Int
+	for i := 0; ; i++ {
+		number, err := recv()
+		if err == io.EOF {
+			if acc == nil {
+				return nil, status.Errorf(codes.InvalidArgument, "at least one number is required")
+			}
+			return acc, nil
+		}
+		if err != nil {
+			log.Printf("Error while reading client stream: %v", err)
+			return nil, err
+		}
+
+		n, err := parseBoundedInt(fmt.Sprintf("messages[%d].number", i), number)
+		if err != nil {
+			return nil, err
+		}
+		n.Abs(n)
+		if acc == nil {
+			acc = n
+			continue
+		}
+		if err := combine(acc, n); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (*server) Gcd(stream calculatorpb.CalculatorService_GcdServer) error {
+	log.Println("Received Gcd RPC")
+	gcd, err := foldNumbers(func() (string, error) {
+		recv, err := stream.Recv()
+		return recv.GetNumber(), err
+	}, func(acc, n *big.Int) error {
+		acc.GCD(nil, nil, acc, n)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return stream.SendAndClose(&calculatorpb.GcdResponse{
+		Gcd: gcd.String(),
+	})
+}
+
+func (*server) Lcm(stream calculatorpb.CalculatorService_LcmServer) error {
+	log.Println("Received Lcm RPC")
+	lcm, err := foldNumbers(func() (string, error) {
+		recv, err := stream.Recv()
+		return recv.GetNumber(), err
+	}, func(acc, n *big.Int) error {
+		if acc.Sign() == 0 || n.Sign() == 0 {
+			acc.SetInt64(0)
+			return nil
+		}
+		gcd := new(big.Int).GCD(nil, nil, acc, n)
+		acc.Mul(acc, new(big.Int).Quo(n, gcd))
+		if acc.BitLen() > maxLcmBits {
+			return status.Errorf(codes.OutOfRange, "the LCM exceeds %d bits", maxLcmBits)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return stream.SendAndClose(&calculatorpb.LcmResponse{
+		Lcm: lcm.String(),
+	})
+}
+
+func (*server) ModPow(ctx context.Context, request *calculatorpb.ModPowRequest) (*calculatorpb.ModPowResponse, error) {
+	log.Printf("Received ModPow RPC: %v", request)
+	base, err := parseBoundedInt("base", request.GetBase())
+	if err != nil {
+		return nil, err
+	}
+	exponent, err := parseBoundedInt("exponent", request.GetExponent())
+	if err != nil {
+		return nil, err
+	}
+	modulus, err := parseModulus("modulus", request.GetModulus())
+	if err != nil {
+		return nil, err
+	}
+
+	if exponent.Sign() < 0 {
+		inverse, err := modInverse("base", base, modulus)
+		if err != nil {
+			return nil, err
+		}
+		base, exponent = inverse, new(big.Int).Neg(exponent)
+	}
+	return &calculatorpb.ModPowResponse{
+		Result: new(big.Int).Exp(base, exponent, modulus).String(),
+	}, nil
+}
+
+func (*server) ModInverse(ctx context.Context, request *calculatorpb.ModInverseRequest) (*calculatorpb.ModInverseResponse, error) {
+	log.Printf("Received ModInverse RPC: %v", request)
+	number, err := parseBoundedInt("number", request.GetNumber())
+	if err != nil {
+		return nil, err
+	}
+	modulus, err := parseModulus("modulus", request.GetModulus())
+	if err != nil {
+		return nil, err
+	}
+	inverse, err := modInverse("number", number, modulus)
+	if err != nil {
+		return nil, err
+	}
+	return &calculatorpb.ModInverseResponse{
+		Inverse: inverse.String(),
+	}, nil
+}
+
+// modInverse returns the inverse of the number held by field modulo m, in
+// [0, m).
+func modInverse(field string, n, m *big.Int) (*big.Int, error) {
+	if m.Cmp(big.NewInt(1)) == 0 {
+		return big.NewInt(0), nil
+	}
+	reduced := new(big.Int).Mod(n, m)
+	if gcd := new(big.Int).GCD(nil, nil, reduced, m); gcd.Cmp(big.NewInt(1)) != 0 {
+		return nil, badRequest(field, fmt.Sprintf("%v has no inverse modulo %v, as their GCD is %v", n, m, gcd))
+	}
+	return new(big.Int).ModInverse(reduced, m), nil
+}
+
+func (s *server) Totient(ctx context.Context, request *calculatorpb.TotientRequest) (*calculatorpb.TotientResponse, error) {
+	log.Printf("Received Totient RPC: %v", request)
+	number, err := parseIntBits("number", request.GetNumber(), maxDecompositionBits)
+	if err != nil {
+		return nil, err
+	}
+	if number.Sign() <= 0 {
+		return nil, badRequest("number", fmt.Sprintf("must be positive, got %v", number))
+	}
+	if number.Cmp(big.NewInt(1)) == 0 {
+		return &calculatorpb.TotientResponse{Totient: "1"}, nil
+	}
+
+	// φ(n) = n ∏ (1 - 1/p) over the distinct primes p dividing n.
+	totient := new(big.Int).Set(number)
+	seen := make(map[string]bool)
+	err = s.factors.Stream(ctx, number.String(), computeFactors(number), func(v interface{}) error {
+		p := v.(*big.Int)
+		if seen[p.String()] {
+			return nil
+		}
+		seen[p.String()] = true
+		totient.Quo(totient, p)
+		totient.Mul(totient, new(big.Int).Sub(p, big.NewInt(1)))
+		return nil
+	})
+	if err != nil {
+		return nil, streamError(err)
+	}
+	return &calculatorpb.TotientResponse{
+		Totient: totient.String(),
+	}, nil
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+
+	"github.com/grpc-project02/project/calculator/calculatorpb"
+	"github.com/grpc-project02/project/calculator/expr"
+	"github.com/grpc-project02/project/calculator/numeric"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultTolerance    = 1e-9
+	defaultOdeTolerance = 1e-6
+
+	defaultMaxEvaluations = 1000000
+	maxEvaluations        = 10000000
+
+	defaultMaxIterations = 100
+	maxIterations        = 100000
+
+	defaultMaxSteps = 100000
+	maxSteps        = 1000000
+)
+
+// compileExpression parses the expression held by field as a function of
+// params, in that order, with the other variables fixed to vars.
+func compileExpression(field, expression string, vars map[string]float64, params ...string) (func(args ...float64) (float64, error), error) {
+	tree, err := expr.Parse(expression)
+	if err != nil {
+		return nil, expressionError(field, err)
+	}
+	env := make(map[string]float64, len(vars)+len(params))
+	for name, v := range vars {
+		env[name] = v
+	}
+	for _, p := range params {
+		if _, ok := vars[p]; ok {
+			return nil, badRequest("variables", fmt.Sprintf("must not give a value for %s, which %s is a function of", p, field))
+		}
+	}
+	return func(args ...float64) (float64, error) {
+		for i, p := range params {
+			env[p] = args[i]
+		}
+		return expr.Eval(tree, env)
+	}, nil
+}
+
+// variableName returns the name held by field, or x if it is empty.
+func variableName(field, name string) (string, error) {
+	if name == "" {
+		return "x", nil
+	}
+	if !expr.IsName(name) {
+		return "", badRequest(field, fmt.Sprintf("%q is not a valid variable name", name))
+	}
+	return name, nil
+}
+
+// finite checks that the number held by field is finite.
+func finite(field string, v float64) error {
+	if math.IsInf(v, 0) || math.IsNaN(v) {
+		return badRequest(field, fmt.Sprintf("%v is not a finite number", v))
+	}
+	return nil
+}
+
+// tolerance returns the tolerance held by field, or def if it is zero.
+func tolerance(field string, v, def float64) (float64, error) {
+	if err := finite(field, v); err != nil {
+		return 0, err
+	}
+	if v < 0 {
+		return 0, badRequest(field, fmt.Sprintf("must not be negative, got %v", v))
+	}
+	if v == 0 {
+		return def, nil
+	}
+	return v, nil
+}
+
+// limit returns the limit held by field, or def if it is zero.
+func limit(field string, v uint32, def, max int) (int, error) {
+	if v == 0 {
+		return def, nil
+	}
+	if int64(v) > int64(max) {
+		return 0, badRequest(field, fmt.Sprintf("must be at most %d, got %d", max, v))
+	}
+	return int(v), nil
+}
+
+// numericError converts an error from the numeric package into a status.
+// Errors in evaluating the function are reported against field.
+func numericError(field string, err error) error {
+	var notConverged *numeric.NotConvergedError
+	if errors.As(err, &notConverged) {
+		st := status.New(codes.FailedPrecondition, err.Error())
+		metadata := map[string]string{
+			"iterations": strconv.Itoa(notConverged.Iterations),
+		}
+		if notConverged.Estimate != 0 {
+			metadata["estimate"] = strconv.FormatFloat(notConverged.Estimate, 'g', -1, 64)
+		}
+		detailed, detailErr := st.WithDetails(&errdetails.ErrorInfo{
+			Reason:   "NOT_CONVERGED",
+			Domain:   "calculator",
+			Metadata: metadata,
+		})
+		if detailErr != nil {
+			return st.Err()
+		}
+		return detailed.Err()
+	}
+	var exprErr *expr.Error
+	if errors.As(err, &exprErr) {
+		return expressionError(field, err)
+	}
+	return streamError(err)
+}
+
+func (*server) Integrate(ctx context.Context, request *calculatorpb.IntegrateRequest) (*calculatorpb.IntegrateResponse, error) {
+	log.Printf("Received Integrate RPC: %v", request)
+	variable, err := variableName("variable", request.GetVariable())
+	if err != nil {
+		return nil, err
+	}
+	if err := finite("lower", request.GetLower()); err != nil {
+		return nil, err
+	}
+	if err := finite("upper", request.GetUpper()); err != nil {
+		return nil, err
+	}
+	tol, err := tolerance("tolerance", request.GetTolerance(), defaultTolerance)
+	if err != nil {
+		return nil, err
+	}
+	max, err := limit("max_evaluations", request.GetMaxEvaluations(), defaultMaxEvaluations, maxEvaluations)
+	if err != nil {
+		return nil, err
+	}
+	f, err := compileExpression("expression", request.GetExpression(), request.GetVariables(), variable)
+	if err != nil {
+		return nil, err
+	}
+
+	value, errEstimate, evals, err := numeric.Integrate(ctx, func(x float64) (float64, error) {
+		return f(x)
+	}, request.GetLower(), request.GetUpper(), tol, max)
+	if err != nil {
+		return nil, numericError("expression", err)
+	}
+	return &calculatorpb.IntegrateResponse{
+		Value:         value,
+		ErrorEstimate: errEstimate,
+		Evaluations:   uint32(evals),
+	}, nil
+}
+
+func (*server) FindRoot(ctx context.Context, request *calculatorpb.FindRootRequest) (*calculatorpb.FindRootResponse, error) {
+	log.Printf("Received FindRoot RPC: %v", request)
+	variable, err := variableName("variable", request.GetVariable())
+	if err != nil {
+		return nil, err
+	}
+	tol, err := tolerance("tolerance", request.GetTolerance(), defaultTolerance)
+	if err != nil {
+		return nil, err
+	}
+	max, err := limit("max_iterations", request.GetMaxIterations(), defaultMaxIterations, maxIterations)
+	if err != nil {
+		return nil, err
+	}
+	compiled, err := compileExpression("expression", request.GetExpression(), request.GetVariables(), variable)
+	if err != nil {
+		return nil, err
+	}
+	f := func(x float64) (float64, error) { return compiled(x) }
+
+	var root float64
+	var iterations int
+	switch method := request.GetMethod(); method {
+	case calculatorpb.FindRootRequest_BISECTION:
+		if err := finite("lower", request.GetLower()); err != nil {
+			return nil, err
+		}
+		if err := finite("upper", request.GetUpper()); err != nil {
+			return nil, err
+		}
+		root, iterations, err = numeric.Bisect(ctx, f, request.GetLower(), request.GetUpper(), tol, max)
+		if errors.Is(err, numeric.ErrNoBracket) {
+			return nil, badRequest("upper", err.Error())
+		}
+	case calculatorpb.FindRootRequest_NEWTON:
+		if err := finite("initial_guess", request.GetInitialGuess()); err != nil {
+			return nil, err
+		}
+		root, iterations, err = numeric.Newton(ctx, f, nil, request.GetInitialGuess(), tol, max)
+	default:
+		return nil, badRequest("method", fmt.Sprintf("unknown method %v", method))
+	}
+	if err != nil {
+		return nil, numericError("expression", err)
+	}
+	return &calculatorpb.FindRootResponse{
+		Root:       root,
+		Iterations: uint32(iterations),
+	}, nil
+}
+
+func (*server) SolveOde(request *calculatorpb.SolveOdeRequest, stream calculatorpb.CalculatorService_SolveOdeServer) error {
+	log.Printf("Received SolveOde RPC: %v", request)
+	if err := finite("t0", request.GetT0()); err != nil {
+		return err
+	}
+	if err := finite("y0", request.GetY0()); err != nil {
+		return err
+	}
+	if err := finite("t_end", request.GetTEnd()); err != nil {
+		return err
+	}
+	tol, err := tolerance("tolerance", request.GetTolerance(), defaultOdeTolerance)
+	if err != nil {
+		return err
+	}
+	interval := request.GetSampleInterval()
+	if err := finite("sample_interval", interval); err != nil {
+		return err
+	}
+	if interval < 0 {
+		return badRequest("sample_interval", fmt.Sprintf("must not be negative, got %v", interval))
+	}
+	max, err := limit("max_steps", request.GetMaxSteps(), defaultMaxSteps, maxSteps)
+	if err != nil {
+		return err
+	}
+	f, err := compileExpression("derivative", request.GetDerivative(), request.GetVariables(), "t", "y")
+	if err != nil {
+		return err
+	}
+
+	err = numeric.SolveODE(stream.Context(), func(t, y float64) (float64, error) {
+		return f(t, y)
+	}, request.GetT0(), request.GetY0(), request.GetTEnd(), tol, interval, max, func(t, y float64) error {
+		return stream.Send(&calculatorpb.SolveOdeResponse{T: t, Y: y})
+	})
+	if err != nil {
+		log.Printf("While solving ODE, error occurred %s", err)
+		return numericError("derivative", err)
+	}
+	return nil
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"reflect"
+	"runtime/debug"
+	"time"
+
+	"github.com/grpc-project02/project/calculator/calculatorpb"
+	"github.com/grpc-project02/project/calculator/operations"
+	"github.com/grpc-project02/project/calculator/primes"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// operationTTL is how long finished operations are kept.
+	operationTTL = 1 * time.Hour
+
+	// operationTimeout bounds how long an operation runs.
+	operationTimeout = 1 * time.Hour
+
+	// maxOperations bounds the operations kept, running or finished.
+	maxOperations = 1000
+
+	// maxOperationsPerClient bounds the operations kept for a client; its
+	// oldest finished ones are forgotten first.
+	maxOperationsPerClient = 100
+
+	// maxRunningOperationsPerClient bounds the operations a client runs at
+	// once.
+	maxRunningOperationsPerClient = 4
+)
+
+// operationMethod is a method that can run as an operation.
+type operationMethod struct {
+	input protoreflect.FullName
+	// fullMethod names the method as gRPC does, for the interceptor.
+	fullMethod string
+	run        func(ctx context.Context, request proto.Message) (proto.Message, error)
+}
+
+type operationsServer struct {
+	manager *operations.Manager
+	methods map[protoreflect.FullName]operationMethod
+	// intercept wraps each run like a call, so that it is recorded in the
+	// history.
+	intercept grpc.UnaryServerInterceptor
+}
+
+func newOperationsServer(manager *operations.Manager, calculator *server, matrix *matrixServer, intercept grpc.UnaryServerInterceptor) *operationsServer {
+	s := &operationsServer{
+		manager:   manager,
+		methods:   make(map[protoreflect.FullName]operationMethod),
+		intercept: intercept,
+	}
+	// Only computations that may take long and change nothing can run as
+	// operations; session and server state are reached through ordinary calls.
+	calculatorService := calculatorpb.File_calculator_calculatorpb_calculator_proto.Services().ByName("CalculatorService")
+	s.addMethods(calculatorService, calculator,
+		"BigIntegerArithmetic", "ModPow", "Totient", "Evaluate", "Integrate", "FindRoot", "Differentiate", "EvaluateDistribution")
+	s.addMethods(calculatorpb.File_calculator_calculatorpb_matrix_proto.Services().ByName("MatrixService"), matrix,
+		"Multiply", "Determinant", "Inverse", "Solve")
+
+	factorize := calculatorService.Methods().ByName("PrimeNumberDecomposition")
+	s.methods[factorize.FullName()] = operationMethod{
+		input:      factorize.Input().FullName(),
+		fullMethod: grpcMethodName(factorize),
+		run:        factorizeOperation,
+	}
+	return s
+}
+
+// grpcMethodName returns the full method name gRPC gives md.
+func grpcMethodName(md protoreflect.MethodDescriptor) string {
+	return fmt.Sprintf("/%s/%s", md.Parent().FullName(), md.Name())
+}
+
+// addMethods makes the named unary methods of the service, as implemented by
+// impl, available as operations.
+func (s *operationsServer) addMethods(service protoreflect.ServiceDescriptor, impl interface{}, names ...protoreflect.Name) {
+	for _, name := range names {
+		md := service.Methods().ByName(name)
+		if md == nil || md.IsStreamingClient() || md.IsStreamingServer() {
+			panic(fmt.Sprintf("%s has no unary method %s", service.FullName(), name))
+		}
+		handler := reflect.ValueOf(impl).MethodByName(string(md.Name()))
+		s.methods[md.FullName()] = operationMethod{
+			input:      md.Input().FullName(),
+			fullMethod: grpcMethodName(md),
+			run: func(ctx context.Context, request proto.Message) (proto.Message, error) {
+				out := handler.Call([]reflect.Value{reflect.ValueOf(ctx), reflect.ValueOf(request)})
+				if err, _ := out[1].Interface().(error); err != nil {
+					return nil, err
+				}
+				return out[0].Interface().(proto.Message), nil
+			},
+		}
+	}
+}
+
+// factorizeOperation runs PrimeNumberDecomposition as an operation, limited
+// by the operation timeout rather than maxDecompositionTime.
+func factorizeOperation(ctx context.Context, request proto.Message) (proto.Message, error) {
+	number, err := decompositionInput(request.(*calculatorpb.PrimeNumberDecompositionRequest))
+	if err != nil {
+		return nil, err
+	}
+	result := &calculatorpb.Factorization{}
+	err = primes.Factor(ctx, number, func(p *big.Int) error {
+		result.Primes = append(result.Primes, p.String())
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// operationError converts an error from the operations package into a status.
+func operationError(err error) error {
+	switch {
+	case errors.Is(err, operations.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, operations.ErrTooManyOperations):
+		return status.Errorf(codes.ResourceExhausted, "%v; at most %d are kept", err, maxOperations)
+	case errors.Is(err, operations.ErrTooManyRunning):
+		return status.Errorf(codes.ResourceExhausted, "%v; at most %d may run at once per client", err, maxRunningOperationsPerClient)
+	}
+	return status.Errorf(codes.Internal, "operation: %v", err)
+}
+
+// operationProto describes the current state of o.
+func (s *operationsServer) operationProto(o *operations.Operation) (*calculatorpb.Operation, error) {
+	op := &calculatorpb.Operation{
+		Name: o.Name,
+		Metadata: &calculatorpb.OperationMetadata{
+			Method:     o.Method,
+			CreateTime: timestamppb.New(o.Created),
+		},
+	}
+	done, ended, result, err := o.Result()
+	if !done {
+		return op, nil
+	}
+
+	op.Done = true
+	op.Metadata.EndTime = timestamppb.New(ended)
+	op.Metadata.ExpireTime = timestamppb.New(ended.Add(s.manager.TTL()))
+	if err != nil {
+		op.Result = &calculatorpb.Operation_Error{Error: calculateError(err)}
+		return op, nil
+	}
+	response, err := anypb.New(result.(proto.Message))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "encoding the response: %v", err)
+	}
+	op.Result = &calculatorpb.Operation_Response{Response: response}
+	return op, nil
+}
+
+func (s *operationsServer) StartOperation(ctx context.Context, request *calculatorpb.StartOperationRequest) (*calculatorpb.Operation, error) {
+	log.Printf("Received StartOperation RPC: %v", request.GetMethod())
+	method, ok := s.methods[protoreflect.FullName(request.GetMethod())]
+	if !ok {
+		return nil, badRequest("method", fmt.Sprintf("%q cannot run as an operation", request.GetMethod()))
+	}
+	if request.GetRequest() == nil {
+		return nil, badRequest("request", "a request is required")
+	}
+	input, err := request.GetRequest().UnmarshalNew()
+	if err != nil {
+		return nil, badRequest("request", err.Error())
+	}
+	if name := input.ProtoReflect().Descriptor().FullName(); name != method.input {
+		return nil, badRequest("request", fmt.Sprintf("%s takes a %s, got a %s", request.GetMethod(), method.input, name))
+	}
+
+	// The run sees the caller and its metadata, as the call would have.
+	p, hasPeer := peer.FromContext(ctx)
+	md, _ := metadata.FromIncomingContext(ctx)
+	o, err := s.manager.Start(clientIdentity(ctx), request.GetMethod(), func(runCtx context.Context) (interface{}, error) {
+		if hasPeer {
+			runCtx = peer.NewContext(runCtx, p)
+		}
+		runCtx = metadata.NewIncomingContext(runCtx, md)
+		return s.run(runCtx, method, input)
+	})
+	if err != nil {
+		return nil, operationError(err)
+	}
+	log.Printf("Started %v running %v", o.Name, o.Method)
+	return s.operationProto(o)
+}
+
+// run calls method through the interceptor. A panic fails the operation with
+// an Internal error instead of crashing the server.
+func (s *operationsServer) run(ctx context.Context, method operationMethod, input proto.Message) (result interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Operation running %v panicked: %v\n%s", method.fullMethod, r, debug.Stack())
+			result, err = nil, status.Errorf(codes.Internal, "%v panicked: %v", method.fullMethod, r)
+		}
+	}()
+	handler := func(ctx context.Context, request interface{}) (interface{}, error) {
+		return method.run(ctx, request.(proto.Message))
+	}
+	if s.intercept == nil {
+		return handler(ctx, input)
+	}
+	return s.intercept(ctx, input, &grpc.UnaryServerInfo{Server: s, FullMethod: method.fullMethod}, handler)
+}
+
+func (s *operationsServer) GetOperation(ctx context.Context, request *calculatorpb.GetOperationRequest) (*calculatorpb.Operation, error) {
+	log.Printf("Received GetOperation RPC: %v", request)
+	o, err := s.manager.Get(clientIdentity(ctx), request.GetName())
+	if err != nil {
+		return nil, operationError(err)
+	}
+	return s.operationProto(o)
+}
+
+func (s *operationsServer) CancelOperation(ctx context.Context, request *calculatorpb.CancelOperationRequest) (*calculatorpb.CancelOperationResponse, error) {
+	log.Printf("Received CancelOperation RPC: %v", request)
+	if err := s.manager.Cancel(clientIdentity(ctx), request.GetName()); err != nil {
+		return nil, operationError(err)
+	}
+	return &calculatorpb.CancelOperationResponse{}, nil
+}
+
+func (s *operationsServer) WaitOperation(ctx context.Context, request *calculatorpb.WaitOperationRequest) (*calculatorpb.Operation, error) {
+	log.Printf("Received WaitOperation RPC: %v", request)
+	o, err := s.manager.Get(clientIdentity(ctx), request.GetName())
+	if err != nil {
+		return nil, operationError(err)
+	}
+	if timeout := request.GetTimeout(); timeout != nil {
+		if err := timeout.CheckValid(); err != nil || timeout.AsDuration() < 0 {
+			return nil, badRequest("timeout", "must be a non-negative duration")
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout.AsDuration())
+		defer cancel()
+	}
+	o.Wait(ctx)
+	return s.operationProto(o)
+}
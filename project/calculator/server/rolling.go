@@ -0,0 +1,103 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/grpc-project02/project/calculator/calculatorpb"
+	"github.com/grpc-project02/project/calculator/rolling"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// maxWindowSize and maxTopK bound the memory of one RollingAggregate call.
+	// A window bounded only by time holds at most maxWindowSize values.
+	maxWindowSize = 1000000
+	maxTopK       = 1000
+)
+
+func (*server) RollingAggregate(stream calculatorpb.CalculatorService_RollingAggregateServer) error {
+	log.Println("Received RollingAggregate RPC")
+
+	var aggregate func(number int64) (*calculatorpb.RollingAggregateResponse, error)
+	for i := 0; ; i++ {
+		recv, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			log.Printf("Error while reading client stream: %v", err)
+			return err
+		}
+
+		if i == 0 {
+			if aggregate, err = newAggregate(recv.GetAggregation()); err != nil {
+				return err
+			}
+		}
+
+		response, err := aggregate(recv.GetNumber())
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(response); err != nil {
+			log.Printf("Error while sending client stream: %v", err)
+			return err
+		}
+	}
+}
+
+// newAggregate validates the aggregation asked for in the first message and
+// returns a function computing the response to each number.
+func newAggregate(aggregation *calculatorpb.Aggregation) (func(number int64) (*calculatorpb.RollingAggregateResponse, error), error) {
+	switch kind := aggregation.GetKind(); kind {
+	case calculatorpb.Aggregation_WINDOW_MAX, calculatorpb.Aggregation_WINDOW_MIN:
+		size := int64(aggregation.GetWindowSize())
+		var span time.Duration
+		if d := aggregation.GetWindowDuration(); d != nil {
+			if err := d.CheckValid(); err != nil || d.AsDuration() <= 0 {
+				return nil, badRequest("messages[0].aggregation.window_duration", "must be a positive duration")
+			}
+			span = d.AsDuration()
+		}
+		if size == 0 && span == 0 {
+			return nil, badRequest("messages[0].aggregation", "window_size or window_duration is required for a window aggregation")
+		}
+		if size > maxWindowSize {
+			return nil, badRequest("messages[0].aggregation.window_size", fmt.Sprintf("must be at most %d", maxWindowSize))
+		}
+
+		window := rolling.NewMaxWindow(size, span, maxWindowSize)
+		if kind == calculatorpb.Aggregation_WINDOW_MIN {
+			window = rolling.NewMinWindow(size, span, maxWindowSize)
+		}
+		return func(number int64) (*calculatorpb.RollingAggregateResponse, error) {
+			value, err := window.Add(number, time.Now())
+			if errors.Is(err, rolling.ErrWindowFull) {
+				return nil, status.Errorf(codes.ResourceExhausted,
+					"the window would hold more than %d values; set a window_size or a shorter window_duration", maxWindowSize)
+			}
+			if err != nil {
+				return nil, status.Errorf(codes.Internal, "aggregating: %v", err)
+			}
+			return &calculatorpb.RollingAggregateResponse{Value: value}, nil
+		}, nil
+
+	case calculatorpb.Aggregation_TOP_K:
+		k := aggregation.GetK()
+		if k == 0 || k > maxTopK {
+			return nil, badRequest("messages[0].aggregation.k", fmt.Sprintf("must be between 1 and %d", maxTopK))
+		}
+		topK := rolling.NewTopK(int(k))
+		return func(number int64) (*calculatorpb.RollingAggregateResponse, error) {
+			return &calculatorpb.RollingAggregateResponse{Top: topK.Add(number)}, nil
+		}, nil
+
+	default:
+		return nil, badRequest("messages[0].aggregation.kind", fmt.Sprintf("unknown aggregation %v", kind))
+	}
+}
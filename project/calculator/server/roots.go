@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+
+	"github.com/grpc-project02/project/calculator/calculatorpb"
+)
+
+const (
+	maxRootDegree = 1000
+
+	// maxPrecision is the most significant digits a float64 can round-trip.
+	maxPrecision = 17
+)
+
+func (*server) NthRoot(ctx context.Context, request *calculatorpb.NthRootRequest) (*calculatorpb.NthRootResponse, error) {
+	log.Printf("Received NthRoot RPC: %v", request)
+	x := request.GetNumber()
+	n := request.GetDegree()
+	if n == 0 {
+		n = 2
+	}
+	precision := int(request.GetPrecision())
+
+	if math.IsNaN(x) || math.IsInf(x, 0) {
+		return nil, badRequest("number", fmt.Sprintf("%v is not a finite number", x))
+	}
+	if n > maxRootDegree {
+		return nil, badRequest("degree", fmt.Sprintf("must be at most %d", maxRootDegree))
+	}
+	if precision > maxPrecision {
+		return nil, badRequest("precision", fmt.Sprintf("must be at most %d significant digits", maxPrecision))
+	}
+	if x < 0 && n%2 == 0 && !request.GetAllowComplex() {
+		return nil, badRequest("number", fmt.Sprintf("%v has no real root of even degree %d; set allow_complex for a complex result", x, n))
+	}
+
+	res := &calculatorpb.NthRootResponse{}
+	if x < 0 && n%2 == 0 {
+		res.Root = roundComplex(complexRoot(x, n, 0), precision)
+	} else {
+		// Odd roots of negative numbers have a real principal root.
+		res.Root = &calculatorpb.Complex{Real: round(realRoot(x, n), precision)}
+	}
+	if request.GetAllRoots() {
+		for k := uint32(0); k < n; k++ {
+			res.AllRoots = append(res.AllRoots, roundComplex(complexRoot(x, n, k), precision))
+		}
+	}
+	return res, nil
+}
+
+// realRoot returns the real n-th root of x, which must be non-negative unless
+// n is odd.
+func realRoot(x float64, n uint32) float64 {
+	switch {
+	case x == 0 || n == 1:
+		return x
+	case n == 2:
+		return math.Sqrt(x)
+	case n == 3:
+		return math.Cbrt(x)
+	}
+
+	a := math.Abs(x)
+	r := math.Pow(a, 1/float64(n))
+	// math.Pow(a, 1/n) inherits the rounding error of 1/n; keep a Newton step
+	// only when it brings r^n closer to a.
+	if r != 0 && !math.IsInf(r, 0) {
+		better := r - (math.Pow(r, float64(n))-a)/(float64(n)*math.Pow(r, float64(n-1)))
+		if math.Abs(math.Pow(better, float64(n))-a) < math.Abs(math.Pow(r, float64(n))-a) {
+			r = better
+		}
+	}
+	if x < 0 {
+		return -r
+	}
+	return r
+}
+
+// complexRoot returns the k-th of the n complex n-th roots of x, counting
+// counter-clockwise from the principal one.
+func complexRoot(x float64, n, k uint32) *calculatorpb.Complex {
+	magnitude := realRoot(math.Abs(x), n)
+	arg := 0.0
+	if x < 0 {
+		arg = math.Pi
+	}
+	angle := (arg + 2*math.Pi*float64(k)) / float64(n)
+	re, im := magnitude*math.Cos(angle), magnitude*math.Sin(angle)
+
+	// Snap the rounding noise of cos and sin near the axes to zero.
+	const eps = 1e-15
+	if math.Abs(re) < eps*magnitude {
+		re = 0
+	}
+	if math.Abs(im) < eps*magnitude {
+		im = 0
+	}
+	return &calculatorpb.Complex{Real: re, Imaginary: im}
+}
+
+func roundComplex(c *calculatorpb.Complex, precision int) *calculatorpb.Complex {
+	return &calculatorpb.Complex{
+		Real:      round(c.GetReal(), precision),
+		Imaginary: round(c.GetImaginary(), precision),
+	}
+}
+
+// round rounds v to the given number of significant digits; 0 leaves it as is.
+func round(v float64, precision int) float64 {
+	if precision == 0 {
+		return v
+	}
+	r, _ := strconv.ParseFloat(strconv.FormatFloat(v, 'g', precision, 64), 64)
+	return r
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/grpc-project02/project/calculator/calculatorpb"
+	"github.com/grpc-project02/project/calculator/distribution"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// seedMetadataKey is the response header carrying the seed Sample used.
+	seedMetadataKey = "calculator-seed"
+
+	// maxDistributionPoints bounds the points of an EvaluateDistribution
+	// request.
+	maxDistributionPoints = 100000
+)
+
+// distributionInput converts the distribution held by field.
+func distributionInput(field string, d *calculatorpb.Distribution) (distribution.Distribution, error) {
+	var result distribution.Distribution
+	var err error
+	switch d := d.GetDistribution().(type) {
+	case *calculatorpb.Distribution_Uniform:
+		field += ".uniform"
+		result, err = distribution.NewUniform(d.Uniform.GetMin(), d.Uniform.GetMax())
+	case *calculatorpb.Distribution_Normal:
+		field += ".normal"
+		result, err = distribution.NewNormal(d.Normal.GetMean(), d.Normal.GetStddev())
+	case *calculatorpb.Distribution_Poisson:
+		field += ".poisson"
+		result, err = distribution.NewPoisson(d.Poisson.GetLambda())
+	case *calculatorpb.Distribution_Exponential:
+		field += ".exponential"
+		result, err = distribution.NewExponential(d.Exponential.GetRate())
+	default:
+		return nil, badRequest(field, "a distribution is required")
+	}
+	var paramErr *distribution.ParameterError
+	if errors.As(err, &paramErr) {
+		return nil, badRequest(field+"."+paramErr.Name, paramErr.Msg)
+	}
+	if err != nil {
+		return nil, badRequest(field, err.Error())
+	}
+	return result, nil
+}
+
+func (*server) Sample(request *calculatorpb.SampleRequest, stream calculatorpb.CalculatorService_SampleServer) error {
+	log.Printf("Received Sample RPC: %v", request)
+	dist, err := distributionInput("distribution", request.GetDistribution())
+	if err != nil {
+		return err
+	}
+	rate := request.GetRate()
+	if math.IsInf(rate, 0) || math.IsNaN(rate) || rate < 0 {
+		return badRequest("rate", fmt.Sprintf("must be a non-negative number, got %v", rate))
+	}
+	seed := time.Now().UnixNano()
+	if request.Seed != nil {
+		seed = request.GetSeed()
+	}
+	if err := stream.SendHeader(metadata.Pairs(seedMetadataKey, strconv.FormatInt(seed, 10))); err != nil {
+		return err
+	}
+
+	ctx := stream.Context()
+	r := rand.New(rand.NewSource(seed))
+	start := time.Now()
+	for i := uint64(0); request.GetCount() == 0 || i < request.GetCount(); i++ {
+		if rate > 0 {
+			// Pace against the start rather than the previous value, so
+			// that timer granularity does not lower the rate.
+			due := start.Add(time.Duration(float64(i) / rate * float64(time.Second)))
+			if err := sleepUntil(ctx, due); err != nil {
+				return streamError(err)
+			}
+		} else if err := ctx.Err(); err != nil {
+			return streamError(err)
+		}
+		v := dist.Sample(r)
+		if request.GetRound() {
+			v = math.Round(v)
+		}
+		if err := stream.Send(&calculatorpb.SampleResponse{Value: v}); err != nil {
+			log.Printf("While sampling, error occurred %s", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// sleepUntil waits until t or until ctx is done.
+func sleepUntil(ctx context.Context, t time.Time) error {
+	wait := time.Until(t)
+	if wait <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (*server) EvaluateDistribution(ctx context.Context, request *calculatorpb.EvaluateDistributionRequest) (*calculatorpb.EvaluateDistributionResponse, error) {
+	log.Printf("Received EvaluateDistribution RPC: %v", request.GetDistribution())
+	dist, err := distributionInput("distribution", request.GetDistribution())
+	if err != nil {
+		return nil, err
+	}
+	if len(request.GetPoints()) > maxDistributionPoints {
+		return nil, badRequest("points", fmt.Sprintf("at most %d points are supported, got %d", maxDistributionPoints, len(request.GetPoints())))
+	}
+	res := &calculatorpb.EvaluateDistributionResponse{}
+	for i, x := range request.GetPoints() {
+		if err := ctx.Err(); err != nil {
+			return nil, streamError(err)
+		}
+		if math.IsNaN(x) {
+			return nil, badRequest(fmt.Sprintf("points[%d]", i), "must be a number")
+		}
+		res.Values = append(res.Values, &calculatorpb.DistributionValue{
+			X:   x,
+			Pdf: dist.PDF(x),
+			Cdf: dist.CDF(x),
+		})
+	}
+	return res, nil
+}
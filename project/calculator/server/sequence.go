@@ -0,0 +1,95 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+
+	"github.com/grpc-project02/project/calculator/calculatorpb"
+	"github.com/grpc-project02/project/calculator/sequence"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// maxSequenceBits bounds the terms GenerateSequence streams.
+	maxSequenceBits = 1 << 18
+
+	// maxRecurrenceOrder bounds the number of coefficients of a linear
+	// recurrence.
+	maxRecurrenceOrder = 8
+)
+
+// recurrenceInput parses the coefficients and initial terms of a linear
+// recurrence.
+func recurrenceInput(request *calculatorpb.GenerateSequenceRequest) ([]*big.Int, []*big.Int, error) {
+	k := len(request.GetCoefficients())
+	if k == 0 || k > maxRecurrenceOrder {
+		return nil, nil, badRequest("coefficients", fmt.Sprintf("between 1 and %d are required, got %d", maxRecurrenceOrder, k))
+	}
+	if len(request.GetInitialTerms()) != k {
+		return nil, nil, badRequest("initial_terms", fmt.Sprintf("one per coefficient is required, got %d for %d", len(request.GetInitialTerms()), k))
+	}
+	coefficients := make([]*big.Int, k)
+	initial := make([]*big.Int, k)
+	for i := 0; i < k; i++ {
+		var err error
+		if coefficients[i], err = parseBoundedInt(fmt.Sprintf("coefficients[%d]", i), request.GetCoefficients()[i]); err != nil {
+			return nil, nil, err
+		}
+		if initial[i], err = parseBoundedInt(fmt.Sprintf("initial_terms[%d]", i), request.GetInitialTerms()[i]); err != nil {
+			return nil, nil, err
+		}
+	}
+	return coefficients, initial, nil
+}
+
+func (*server) GenerateSequence(request *calculatorpb.GenerateSequenceRequest, stream calculatorpb.CalculatorService_GenerateSequenceServer) error {
+	log.Printf("Received GenerateSequence RPC: %v", request)
+	start := request.GetStart()
+	var gen sequence.Generator
+	var err error
+	switch seq := request.GetSequence(); seq {
+	case calculatorpb.GenerateSequenceRequest_FIBONACCI:
+		gen, err = sequence.Fibonacci(start, maxSequenceBits)
+	case calculatorpb.GenerateSequenceRequest_FACTORIAL:
+		gen, err = sequence.Factorial(start, maxSequenceBits)
+	case calculatorpb.GenerateSequenceRequest_CATALAN:
+		gen, err = sequence.Catalan(start, maxSequenceBits)
+	case calculatorpb.GenerateSequenceRequest_LINEAR_RECURRENCE:
+		coefficients, initial, inputErr := recurrenceInput(request)
+		if inputErr != nil {
+			return inputErr
+		}
+		gen, err = sequence.LinearRecurrence(coefficients, initial, start, maxSequenceBits)
+	default:
+		return badRequest("sequence", fmt.Sprintf("unknown sequence %v", seq))
+	}
+	if errors.Is(err, sequence.ErrTooLarge) {
+		return status.Errorf(codes.OutOfRange, "the terms from index %d on have more than %d bits", start, maxSequenceBits)
+	}
+	if err != nil {
+		return status.Errorf(codes.Internal, "generating the sequence: %v", err)
+	}
+
+	ctx := stream.Context()
+	for i := uint64(0); request.GetCount() == 0 || i < request.GetCount(); i++ {
+		if err := ctx.Err(); err != nil {
+			return streamError(err)
+		}
+		term := gen.Next()
+		if term.BitLen() > maxSequenceBits {
+			return status.Errorf(codes.OutOfRange, "term %d has more than %d bits", start+i, maxSequenceBits)
+		}
+		err := stream.Send(&calculatorpb.GenerateSequenceResponse{
+			Index: start + i,
+			Term:  term.String(),
+		})
+		if err != nil {
+			log.Printf("While generating sequence, error occurred %s", err)
+			return err
+		}
+	}
+	return nil
+}
@@ -4,7 +4,14 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/grpc-project02/project/calculator/cache"
 	"github.com/grpc-project02/project/calculator/calculatorpb"
+	"github.com/grpc-project02/project/calculator/expr"
+	"github.com/grpc-project02/project/calculator/history"
+	"github.com/grpc-project02/project/calculator/operations"
+	"github.com/grpc-project02/project/calculator/primes"
+	"github.com/grpc-project02/project/calculator/session"
+	"github.com/grpc-project02/project/internal/serverconfig"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/reflection"
@@ -13,10 +20,51 @@ import (
 	"io"
 	"log"
 	"math"
+	"math/big"
 	"net"
+	"os"
+	"strconv"
+	"time"
 )
 
 type server struct {
+	sessions *session.Store
+	history  history.Store
+	// auditor reports whether a client may list every caller's history.
+	auditor func(client string) bool
+
+	// factors and roots cache PrimeNumberDecomposition and SquareRoot.
+	factors *cache.Cache
+	roots   *cache.Cache
+}
+
+func (s *server) Evaluate(ctx context.Context, request *calculatorpb.EvaluateRequest) (*calculatorpb.EvaluateResponse, error) {
+	log.Printf("Received Evaluate RPC: %v", request)
+	sess, err := s.optionalSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := expr.Parse(request.GetExpression())
+	if err != nil {
+		return nil, expressionError("expression", err)
+	}
+	variables := request.GetVariables()
+	if sess != nil {
+		variables = sess.Variables()
+		for name, v := range request.GetVariables() {
+			variables[name] = v
+		}
+	}
+	result, err := expr.Eval(tree, variables)
+	if err != nil {
+		return nil, expressionError("expression", err)
+	}
+	if sess != nil {
+		sess.Record(result)
+	}
+	return &calculatorpb.EvaluateResponse{
+		Result: result,
+	}, nil
 }
 
 func (s *server) SquareRoot(ctx context.Context, request *calculatorpb.SquareRootRequest) (*calculatorpb.SquareRootResponse, error) {
@@ -26,8 +74,22 @@ func (s *server) SquareRoot(ctx context.Context, request *calculatorpb.SquareRoo
 			codes.InvalidArgument,
 			fmt.Sprintf("Received a negative number %v", number))
 	}
+	var root float64
+	err := s.roots.Stream(ctx, strconv.FormatInt(number, 10), func(ctx context.Context, emit func(v interface{})) error {
+		emit(math.Sqrt(float64(number)))
+		return nil
+	}, func(v interface{}) error {
+		root = v.(float64)
+		return nil
+	})
+	if err != nil {
+		return nil, streamError(err)
+	}
+	if err := s.record(ctx, root); err != nil {
+		return nil, err
+	}
 	return &calculatorpb.SquareRootResponse{
-		NumberRoot: math.Sqrt(float64(number)),
+		NumberRoot: root,
 	}, nil
 }
 
@@ -66,6 +128,9 @@ func (*server) ComputeAverage(averageServer calculatorpb.CalculatorService_Compu
 	for {
 		recv, err := averageServer.Recv()
 		if err == io.EOF {
+			if cnt == 0 {
+				return status.Errorf(codes.InvalidArgument, "cannot average an empty stream")
+			}
 			average = float64(sum) / float64(cnt)
 			return averageServer.SendAndClose(&calculatorpb.ComputeAverageResponse{
 				Average: average,
@@ -74,7 +139,8 @@ func (*server) ComputeAverage(averageServer calculatorpb.CalculatorService_Compu
 		}
 
 		if err != nil {
-			log.Fatalf("Error while reading client stream: %v", err)
+			log.Printf("Error while reading client stream: %v", err)
+			return err
 		}
 
 		sum += recv.GetNumber()
@@ -82,32 +148,107 @@ func (*server) ComputeAverage(averageServer calculatorpb.CalculatorService_Compu
 	}
 }
 
-func (*server) PrimeNumberDecomposition(request *calculatorpb.PrimeNumberDecompositionRequest, stream calculatorpb.CalculatorService_PrimeNumberDecompositionServer) error {
+func (s *server) PrimeNumberDecomposition(request *calculatorpb.PrimeNumberDecompositionRequest, stream calculatorpb.CalculatorService_PrimeNumberDecompositionServer) error {
 	log.Printf("Received PrimeNumberDecomposition RPC: %v", request)
-	number := request.GetNumber()
-	k := int64(2)
+	number, err := decompositionInput(request)
+	if err != nil {
+		return err
+	}
 
-	for number > 1 {
-		if number%k == 0 {
-			number /= k
-			response := &calculatorpb.PrimeNumberDecompositionResponse{
-				Prime: k,
-			}
-			err := stream.Send(response)
-			if err != nil {
-				log.Fatalf("While sending response, error occurred %s", err)
-			}
-		} else {
-			k++
+	err = s.factors.Stream(stream.Context(), number.String(), computeFactors(number), func(v interface{}) error {
+		p := v.(*big.Int)
+		response := &calculatorpb.PrimeNumberDecompositionResponse{
+			BigPrime: p.String(),
+		}
+		if p.IsInt64() {
+			response.Prime = p.Int64()
+		}
+		return stream.Send(response)
+	})
+	if err != nil {
+		log.Printf("While decomposing %v, error occurred %s", number, err)
+		return streamError(err)
+	}
+	return nil
+}
+
+const (
+	// maxDecompositionBits bounds the inputs PrimeNumberDecomposition accepts.
+	maxDecompositionBits = 512
+
+	// maxDecompositionTime bounds a decomposition, since large semiprimes
+	// can take practically forever. Each caller's deadline applies on top.
+	maxDecompositionTime = 1 * time.Minute
+
+	factorCacheSize = 10000
+	rootCacheSize   = 10000
+)
+
+// computeFactors factorizes number for the factor cache.
+func computeFactors(number *big.Int) cache.ComputeFunc {
+	return func(ctx context.Context, emit func(v interface{})) error {
+		ctx, cancel := context.WithTimeout(ctx, maxDecompositionTime)
+		defer cancel()
+		return primes.Factor(ctx, number, func(p *big.Int) error {
+			emit(p)
+			return nil
+		})
+	}
+}
+
+// decompositionInput picks the number to factorize out of a request.
+func decompositionInput(request *calculatorpb.PrimeNumberDecompositionRequest) (*big.Int, error) {
+	field := "number"
+	number := big.NewInt(request.GetNumber())
+	if request.GetBigNumber() != "" {
+		if request.GetNumber() != 0 {
+			return nil, badRequest("big_number", "number and big_number are mutually exclusive")
+		}
+		field = "big_number"
+		var err error
+		if number, err = parseIntBits(field, request.GetBigNumber(), maxDecompositionBits); err != nil {
+			return nil, err
 		}
 	}
+	if number.Cmp(big.NewInt(1)) <= 0 {
+		return nil, badRequest(field, fmt.Sprintf("%v cannot be factorized; the number must be greater than 1", number))
+	}
+	return number, nil
+}
+
+func (*server) StreamPrimes(request *calculatorpb.StreamPrimesRequest, stream calculatorpb.CalculatorService_StreamPrimesServer) error {
+	log.Printf("Received StreamPrimes RPC: %v", request)
+	from, to := request.GetFrom(), request.GetTo()
+	if to > primes.MaxRangeLimit {
+		return badRequest("to", fmt.Sprintf("must be at most %d", int64(primes.MaxRangeLimit)))
+	}
+	if from > to {
+		return badRequest("from", fmt.Sprintf("%d is greater than to (%d)", from, to))
+	}
+
+	err := primes.Range(stream.Context(), from, to, func(p int64) error {
+		return stream.Send(&calculatorpb.StreamPrimesResponse{
+			Prime: p,
+		})
+	})
+	if err != nil {
+		log.Printf("While streaming primes, error occurred %s", err)
+		return streamError(err)
+	}
 	return nil
 }
 
-func (*server) Sum(ctx context.Context, request *calculatorpb.SumRequest) (*calculatorpb.SumResponse, error) {
+func (s *server) Sum(ctx context.Context, request *calculatorpb.SumRequest) (*calculatorpb.SumResponse, error) {
 	log.Printf("Recieved Sum RPC: %v", request)
+	sum, err := addInt64(request.GetFirstNumber(), request.GetSecondNumber())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.record(ctx, float64(sum)); err != nil {
+		return nil, err
+	}
 	res := &calculatorpb.SumResponse{
-		Result: request.GetFirstNumber() + request.GetSecondNumber(),
+		Result: sum,
 	}
 	return res, nil
 }
@@ -115,16 +256,92 @@ func (*server) Sum(ctx context.Context, request *calculatorpb.SumRequest) (*calc
 func main() {
 	log.Println("Calculator Server")
 
-	lis, err := net.Listen("tcp", "0.0.0.0:50051")
-	defer lis.Close()
+	cfg, err := serverconfig.Load("calculator", serverconfig.Config{
+		ListenAddr:      "0.0.0.0:50051",
+		Services:        []string{"calculator", "matrix", "operations", "units", "reflection"},
+		MaxRecvMsgSize:  4 << 20,
+		MaxSendMsgSize:  4 << 20,
+		DefaultDeadline: serverconfig.Duration(10 * time.Second),
+		Keepalive: serverconfig.KeepaliveConfig{
+			Time:    serverconfig.Duration(2 * time.Hour),
+			Timeout: serverconfig.Duration(20 * time.Second),
+			MinTime: serverconfig.Duration(5 * time.Minute),
+		},
+	}, []string{"calculator", "matrix", "operations", "units", "reflection"}, os.Args[1:])
 	if err != nil {
-		log.Fatalf("Failed to listen: %v", err)
+		log.Fatalf("Failed to load configuration: %v", err)
 	}
+	log.Printf("Effective configuration:\n%v", cfg)
 
-	s := grpc.NewServer()
-	calculatorpb.RegisterCalculatorServiceServer(s, &server{})
+	opts, err := cfg.ServerOptions()
+	if err != nil {
+		log.Fatalf("Failed to build server options: %v", err)
+	}
 
-	reflection.Register(s)
+	retention := history.Retention{MaxEntries: historyMaxEntries, MaxAge: historyMaxAge, MaxBytes: historyMaxBytes}
+	var store history.Store = history.NewMemoryStore(retention)
+	path, err := cfg.DataPath("history.jsonl")
+	if err != nil {
+		log.Fatalf("Failed to prepare data directory: %v", err)
+	}
+	if path != "" {
+		fileStore, err := history.NewFileStore(path, retention)
+		if err != nil {
+			log.Fatalf("Failed to load history: %v", err)
+		}
+		defer fileStore.Close()
+		store = fileStore
+	}
+	recorder := &historyRecorder{store: store}
+	opts = append(opts,
+		grpc.ChainUnaryInterceptor(recorder.unaryInterceptor),
+		grpc.ChainStreamInterceptor(recorder.streamInterceptor),
+	)
+
+	calculator := &server{
+		sessions: session.NewStore(sessionIdleTimeout, maxSessionsPerClient),
+		history:  store,
+		auditor:  cfg.Auditor,
+		factors:  cache.New(factorCacheSize),
+		roots:    cache.New(rootCacheSize),
+	}
+	matrix := &matrixServer{}
+
+	s := grpc.NewServer(opts...)
+	if cfg.Enabled("calculator") {
+		calculatorpb.RegisterCalculatorServiceServer(s, calculator)
+	}
+	if cfg.Enabled("matrix") {
+		calculatorpb.RegisterMatrixServiceServer(s, matrix)
+	}
+	if cfg.Enabled("operations") {
+		manager := operations.NewManager(operationTTL, operationTimeout, operations.Limits{
+			Total:           maxOperations,
+			PerOwner:        maxOperationsPerClient,
+			RunningPerOwner: maxRunningOperationsPerClient,
+		})
+		calculatorpb.RegisterOperationsServiceServer(s, newOperationsServer(manager, calculator, matrix, recorder.unaryInterceptor))
+	}
+	if cfg.Enabled("units") {
+		path, err := cfg.DataPath("units.json")
+		if err != nil {
+			log.Fatalf("Failed to prepare data directory: %v", err)
+		}
+		table, err := loadUnits(path)
+		if err != nil {
+			log.Fatalf("Failed to load units: %v", err)
+		}
+		calculatorpb.RegisterUnitServiceServer(s, &unitServer{table: table})
+	}
+	if cfg.Enabled("reflection") {
+		reflection.Register(s)
+	}
+
+	lis, err := net.Listen("tcp", cfg.ListenAddr)
+	if err != nil {
+		log.Fatalf("Failed to listen: %v", err)
+	}
+	defer lis.Close()
 
 	if err := s.Serve(lis); err != nil {
 		log.Fatalf("failed to serve: %v\n", err)
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"time"
+
+	"github.com/grpc-project02/project/calculator/calculatorpb"
+	"github.com/grpc-project02/project/calculator/expr"
+	"github.com/grpc-project02/project/calculator/session"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+const (
+	// sessionMetadataKey carries the session id on calls within a session.
+	sessionMetadataKey = "calculator-session"
+
+	sessionIdleTimeout   = 15 * time.Minute
+	maxSessionsPerClient = 16
+)
+
+// clientIdentity names the caller a session belongs to. There is no
+// authentication yet, so the caller is its IP address.
+func clientIdentity(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// sessionID returns the session id sent in the call metadata, if any.
+func sessionID(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if ids := md.Get(sessionMetadataKey); len(ids) > 0 {
+		return ids[0]
+	}
+	return ""
+}
+
+// sessionError converts an error from the session package into a status.
+func sessionError(err error) error {
+	switch {
+	case errors.Is(err, session.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, session.ErrTooManySessions):
+		return status.Errorf(codes.ResourceExhausted, "%v; at most %d are allowed", err, maxSessionsPerClient)
+	case errors.Is(err, session.ErrTooManyVariables):
+		return status.Errorf(codes.ResourceExhausted, "%v; at most %d are allowed", err, session.MaxVariables)
+	}
+	return status.Errorf(codes.Internal, "session: %v", err)
+}
+
+// optionalSession returns the session the call is made in, or nil if it is
+// made outside any session.
+func (s *server) optionalSession(ctx context.Context) (*session.Session, error) {
+	id := sessionID(ctx)
+	if id == "" {
+		return nil, nil
+	}
+	sess, err := s.sessions.Get(clientIdentity(ctx), id)
+	if err != nil {
+		return nil, sessionError(err)
+	}
+	return sess, nil
+}
+
+// requireSession returns the session the call is made in.
+func (s *server) requireSession(ctx context.Context) (*session.Session, error) {
+	sess, err := s.optionalSession(ctx)
+	if err == nil && sess == nil {
+		err = status.Errorf(codes.FailedPrecondition, "the %s metadata key is required; call CreateSession first", sessionMetadataKey)
+	}
+	return sess, err
+}
+
+// record makes result the ans of the session the call is made in, if any.
+func (s *server) record(ctx context.Context, result float64) error {
+	sess, err := s.optionalSession(ctx)
+	if err != nil {
+		return err
+	}
+	if sess != nil {
+		sess.Record(result)
+	}
+	return nil
+}
+
+func (s *server) CreateSession(ctx context.Context, request *calculatorpb.CreateSessionRequest) (*calculatorpb.CreateSessionResponse, error) {
+	log.Println("Received CreateSession RPC")
+	sess, err := s.sessions.Create(clientIdentity(ctx))
+	if err != nil {
+		return nil, sessionError(err)
+	}
+	return &calculatorpb.CreateSessionResponse{
+		SessionId:   sess.ID,
+		IdleTimeout: durationpb.New(sessionIdleTimeout),
+	}, nil
+}
+
+func (s *server) CloseSession(ctx context.Context, request *calculatorpb.CloseSessionRequest) (*calculatorpb.CloseSessionResponse, error) {
+	log.Println("Received CloseSession RPC")
+	sess, err := s.requireSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.sessions.Close(clientIdentity(ctx), sess.ID); err != nil {
+		return nil, sessionError(err)
+	}
+	return &calculatorpb.CloseSessionResponse{}, nil
+}
+
+func (s *server) SetVariable(ctx context.Context, request *calculatorpb.SetVariableRequest) (*calculatorpb.SetVariableResponse, error) {
+	log.Printf("Received SetVariable RPC: %v", request)
+	sess, err := s.requireSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkLength("name", request.GetName(), session.MaxNameLength); err != nil {
+		return nil, err
+	}
+	switch name := request.GetName(); {
+	case !expr.IsName(name):
+		return nil, badRequest("name", fmt.Sprintf("%q is not a valid variable name", name))
+	case name == "ans" || name == "mr":
+		return nil, badRequest("name", fmt.Sprintf("%s is reserved", name))
+	}
+	if err := sess.SetVariable(request.GetName(), request.GetValue()); err != nil {
+		return nil, sessionError(err)
+	}
+	return &calculatorpb.SetVariableResponse{}, nil
+}
+
+func (s *server) Memory(ctx context.Context, request *calculatorpb.MemoryRequest) (*calculatorpb.MemoryResponse, error) {
+	log.Printf("Received Memory RPC: %v", request)
+	sess, err := s.requireSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+	value := sess.Ans()
+	if request.Value != nil {
+		value = request.GetValue()
+	}
+
+	var memory float64
+	switch request.GetOperation() {
+	case calculatorpb.MemoryRequest_RECALL:
+		memory = sess.Memory()
+	case calculatorpb.MemoryRequest_ADD:
+		memory = sess.AddMemory(value)
+	case calculatorpb.MemoryRequest_SUBTRACT:
+		memory = sess.AddMemory(-value)
+	case calculatorpb.MemoryRequest_CLEAR:
+		sess.ClearMemory()
+	default:
+		return nil, badRequest("operation", fmt.Sprintf("unknown operation %v", request.GetOperation()))
+	}
+	return &calculatorpb.MemoryResponse{Memory: memory}, nil
+}
+
+func (s *server) GetSession(ctx context.Context, request *calculatorpb.GetSessionRequest) (*calculatorpb.GetSessionResponse, error) {
+	log.Println("Received GetSession RPC")
+	sess, err := s.requireSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+	variables := sess.Variables()
+	delete(variables, "ans")
+	delete(variables, "mr")
+	return &calculatorpb.GetSessionResponse{
+		Variables: variables,
+		Ans:       sess.Ans(),
+		Memory:    sess.Memory(),
+		History:   sess.History(),
+	}, nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"math"
+
+	"github.com/grpc-project02/project/calculator/calculatorpb"
+	"github.com/grpc-project02/project/calculator/stats"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxPercentiles bounds how many percentiles one ComputeStatistics call may
+// ask for, since each one is tracked separately.
+const maxPercentiles = 100
+
+func (*server) ComputeStatistics(statisticsServer calculatorpb.CalculatorService_ComputeStatisticsServer) error {
+	log.Println("Received ComputeStatistics RPC")
+
+	var acc *stats.Accumulator
+	var percentiles []float64
+	for i := 0; ; i++ {
+		recv, err := statisticsServer.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Printf("Error while reading client stream: %v", err)
+			return err
+		}
+
+		if i == 0 {
+			percentiles = recv.GetPercentiles()
+			if err := validatePercentiles(percentiles); err != nil {
+				return err
+			}
+			quantiles := make([]float64, len(percentiles))
+			for j, p := range percentiles {
+				quantiles[j] = p / 100
+			}
+			acc = stats.NewAccumulator(quantiles)
+		}
+
+		number := recv.GetNumber()
+		if math.IsNaN(number) || math.IsInf(number, 0) {
+			return badRequest(fmt.Sprintf("messages[%d].number", i), fmt.Sprintf("%v is not a finite number", number))
+		}
+		acc.Add(number)
+	}
+
+	if acc == nil {
+		return status.Errorf(codes.InvalidArgument, "cannot compute statistics of an empty stream")
+	}
+
+	res := &calculatorpb.ComputeStatisticsResponse{
+		Count:             acc.Count(),
+		Mean:              acc.Mean(),
+		Variance:          acc.Variance(),
+		StandardDeviation: acc.StdDev(),
+		Min:               acc.Min(),
+		Max:               acc.Max(),
+		Median:            acc.Median(),
+		Approximate:       acc.Approximate(),
+	}
+	for _, p := range percentiles {
+		res.Percentiles = append(res.Percentiles, &calculatorpb.Percentile{
+			Percentile: p,
+			Value:      acc.Quantile(p / 100),
+		})
+	}
+	return statisticsServer.SendAndClose(res)
+}
+
+func validatePercentiles(percentiles []float64) error {
+	if len(percentiles) > maxPercentiles {
+		return badRequest("messages[0].percentiles", fmt.Sprintf("at most %d percentiles may be requested", maxPercentiles))
+	}
+	for j, p := range percentiles {
+		if !(p >= 0 && p <= 100) {
+			return badRequest(fmt.Sprintf("messages[0].percentiles[%d]", j), fmt.Sprintf("%v is not in [0, 100]", p))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/grpc-project02/project/calculator/calculatorpb"
+	"github.com/grpc-project02/project/calculator/units"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type unitServer struct {
+	table *units.Table
+}
+
+// loadUnits returns the built-in unit table, extended with the definitions
+// in the file at path if there is one.
+func loadUnits(path string) (*units.Table, error) {
+	table := units.Default()
+	if path == "" {
+		return table, nil
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return table, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if err := table.Load(f); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	log.Printf("Loaded unit definitions from %v", path)
+	return table, nil
+}
+
+// unitError reports an error from the units package about the unit held by
+// field.
+func unitError(field string, err error) error {
+	var unknown *units.UnknownUnitError
+	var dimension *units.DimensionError
+	switch {
+	case errors.As(err, &unknown), errors.As(err, &dimension), errors.Is(err, units.ErrOffset):
+		return badRequest(field, err.Error())
+	}
+	return badRequest(field, fmt.Sprintf("invalid unit: %v", err))
+}
+
+// quantity parses the quantity held by field.
+func (s *unitServer) quantity(field string, q *calculatorpb.Quantity) (float64, *units.Unit, error) {
+	if q == nil {
+		return 0, nil, badRequest(field, "a quantity is required")
+	}
+	if math.IsNaN(q.GetValue()) || math.IsInf(q.GetValue(), 0) {
+		return 0, nil, badRequest(field+".value", fmt.Sprintf("%v is not a finite number", q.GetValue()))
+	}
+	u, err := s.table.Parse(q.GetUnit())
+	if err != nil {
+		return 0, nil, unitError(field+".unit", err)
+	}
+	return q.GetValue(), u, nil
+}
+
+func (s *unitServer) Convert(ctx context.Context, request *calculatorpb.ConvertRequest) (*calculatorpb.ConvertResponse, error) {
+	log.Printf("Received Convert RPC: %v", request)
+	value, from, err := s.quantity("quantity", request.GetQuantity())
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.table.Parse(request.GetTo())
+	if err != nil {
+		return nil, unitError("to", err)
+	}
+	converted, err := units.Convert(value, from, to)
+	if err != nil {
+		return nil, unitError("to", err)
+	}
+	// Powers of units, such as km^400, can overflow a double.
+	if math.IsInf(converted, 0) || math.IsNaN(converted) {
+		return nil, status.Errorf(codes.OutOfRange, "the result does not fit in a double")
+	}
+	return &calculatorpb.ConvertResponse{
+		Quantity: &calculatorpb.Quantity{Value: converted, Unit: to.Symbol},
+	}, nil
+}
+
+func (s *unitServer) QuantityArithmetic(ctx context.Context, request *calculatorpb.QuantityArithmeticRequest) (*calculatorpb.QuantityArithmeticResponse, error) {
+	log.Printf("Received QuantityArithmetic RPC: %v", request)
+	x, xUnit, err := s.quantity("first", request.GetFirst())
+	if err != nil {
+		return nil, err
+	}
+	y, yUnit, err := s.quantity("second", request.GetSecond())
+	if err != nil {
+		return nil, err
+	}
+
+	var value float64
+	var unit *units.Unit
+	switch op := request.GetOperation(); op {
+	case calculatorpb.ArithmeticOperation_ADD, calculatorpb.ArithmeticOperation_SUBTRACT:
+		if xUnit.Offset != 0 || yUnit.Offset != 0 {
+			return nil, badRequest("second.unit", units.ErrOffset.Error())
+		}
+		if xUnit.Dimension != yUnit.Dimension {
+			return nil, badRequest("second.unit", fmt.Sprintf("cannot %s %s (%v) and %s (%v)",
+				strings.ToLower(op.String()), xUnit, xUnit.Dimension, yUnit, yUnit.Dimension))
+		}
+		y, _ = units.Convert(y, yUnit, xUnit)
+		value, unit = x+y, xUnit
+		if op == calculatorpb.ArithmeticOperation_SUBTRACT {
+			value = x - y
+		}
+	case calculatorpb.ArithmeticOperation_MULTIPLY:
+		if unit, err = units.Mul(xUnit, yUnit); err != nil {
+			return nil, unitError("second.unit", err)
+		}
+		value = x * y
+	case calculatorpb.ArithmeticOperation_DIVIDE:
+		if y == 0 {
+			return nil, badRequest("second.value", "division by zero")
+		}
+		if unit, err = units.Quo(xUnit, yUnit); err != nil {
+			return nil, unitError("second.unit", err)
+		}
+		value = x / y
+	default:
+		return nil, badRequest("operation", fmt.Sprintf("unknown operation %v", op))
+	}
+
+	if request.ResultUnit != nil {
+		to, err := s.table.Parse(request.GetResultUnit())
+		if err != nil {
+			return nil, unitError("result_unit", err)
+		}
+		if value, err = units.Convert(value, unit, to); err != nil {
+			return nil, unitError("result_unit", err)
+		}
+		unit = to
+	}
+	if math.IsInf(value, 0) || math.IsNaN(value) {
+		return nil, status.Errorf(codes.OutOfRange, "the result does not fit in a double")
+	}
+	return &calculatorpb.QuantityArithmeticResponse{
+		Result: &calculatorpb.Quantity{Value: value, Unit: unit.Symbol},
+	}, nil
+}
+
+func (s *unitServer) ListUnits(ctx context.Context, request *calculatorpb.ListUnitsRequest) (*calculatorpb.ListUnitsResponse, error) {
+	log.Printf("Received ListUnits RPC: %v", request)
+	res := &calculatorpb.ListUnitsResponse{}
+	for _, u := range s.table.Units() {
+		dimension := u.Dimension.String()
+		if request.GetDimension() != "" && request.GetDimension() != dimension {
+			continue
+		}
+		res.Units = append(res.Units, &calculatorpb.UnitInfo{
+			Symbol:    u.Symbol,
+			Name:      u.Name,
+			Aliases:   u.Aliases,
+			Dimension: dimension,
+			Factor:    u.Factor,
+			Offset:    u.Offset,
+		})
+	}
+	return res, nil
+}
@@ -0,0 +1,221 @@
+// Package session keeps per-client calculator state between calls: named
+// variables, the last result, a memory register and a bounded history.
+package session
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned for unknown, expired or foreign sessions.
+	ErrNotFound = errors.New("session not found or expired")
+
+	// ErrTooManySessions is returned when a client already holds the
+	// maximum number of sessions.
+	ErrTooManySessions = errors.New("too many sessions for this client")
+
+	// ErrTooManyVariables is returned when a session already holds
+	// MaxVariables variables.
+	ErrTooManyVariables = errors.New("too many variables in this session")
+)
+
+const (
+	// HistoryLimit is how many results a session remembers.
+	HistoryLimit = 100
+
+	// MaxVariables is how many variables a session holds.
+	MaxVariables = 256
+
+	// MaxNameLength bounds the length of variable names, in bytes.
+	MaxNameLength = 64
+)
+
+// Session is the state of one session. Its methods are safe for concurrent
+// use.
+type Session struct {
+	ID     string
+	client string
+
+	mu        sync.Mutex
+	lastUsed  time.Time
+	variables map[string]float64
+	ans       float64
+	memory    float64
+	history   []float64
+}
+
+// Variables returns a copy of the session variables along with ans, the last
+// result, and mr, the memory register.
+func (s *Session) Variables() map[string]float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	vars := make(map[string]float64, len(s.variables)+2)
+	for name, v := range s.variables {
+		vars[name] = v
+	}
+	vars["ans"] = s.ans
+	vars["mr"] = s.memory
+	return vars
+}
+
+// SetVariable sets a variable. A new one fails with ErrTooManyVariables if
+// the session already holds MaxVariables.
+func (s *Session) SetVariable(name string, value float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.variables[name]; !ok && len(s.variables) >= MaxVariables {
+		return ErrTooManyVariables
+	}
+	s.variables[name] = value
+	return nil
+}
+
+// Record makes value the last result, ans, and appends it to the history.
+func (s *Session) Record(value float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ans = value
+	s.history = append(s.history, value)
+	if len(s.history) > HistoryLimit {
+		s.history = append(s.history[:0], s.history[len(s.history)-HistoryLimit:]...)
+	}
+}
+
+// Ans returns the last result.
+func (s *Session) Ans() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ans
+}
+
+// AddMemory adds delta to the memory register and returns its new value.
+func (s *Session) AddMemory(delta float64) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.memory += delta
+	return s.memory
+}
+
+// Memory returns the memory register.
+func (s *Session) Memory() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.memory
+}
+
+// ClearMemory resets the memory register to 0.
+func (s *Session) ClearMemory() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.memory = 0
+}
+
+// History returns the remembered results, oldest first.
+func (s *Session) History() []float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]float64(nil), s.history...)
+}
+
+// Store holds the live sessions. Idle sessions expire after the store's idle
+// timeout and are swept lazily, whenever a session is created.
+type Store struct {
+	idleTimeout  time.Duration
+	maxPerClient int
+
+	mu       sync.Mutex
+	sessions map[string]*Session
+}
+
+// NewStore returns an empty Store.
+func NewStore(idleTimeout time.Duration, maxPerClient int) *Store {
+	return &Store{
+		idleTimeout:  idleTimeout,
+		maxPerClient: maxPerClient,
+		sessions:     make(map[string]*Session),
+	}
+}
+
+// Create starts a session owned by client.
+func (st *Store) Create(client string) (*Session, error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	now := time.Now()
+	owned := 0
+	for id, s := range st.sessions {
+		if st.expired(s, now) {
+			delete(st.sessions, id)
+		} else if s.client == client {
+			owned++
+		}
+	}
+	if owned >= st.maxPerClient {
+		return nil, ErrTooManySessions
+	}
+
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+	s := &Session{
+		ID:        id,
+		client:    client,
+		lastUsed:  now,
+		variables: make(map[string]float64),
+	}
+	st.sessions[id] = s
+	return s, nil
+}
+
+// Get returns the session with the given id if it is owned by client, and
+// marks it as used.
+func (st *Store) Get(client, id string) (*Session, error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	s := st.sessions[id]
+	if s == nil || s.client != client {
+		return nil, ErrNotFound
+	}
+	now := time.Now()
+	if st.expired(s, now) {
+		delete(st.sessions, id)
+		return nil, ErrNotFound
+	}
+	s.mu.Lock()
+	s.lastUsed = now
+	s.mu.Unlock()
+	return s, nil
+}
+
+// Close ends the session with the given id if it is owned by client.
+func (st *Store) Close(client, id string) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	s := st.sessions[id]
+	if s == nil || s.client != client {
+		return ErrNotFound
+	}
+	delete(st.sessions, id)
+	return nil
+}
+
+func (st *Store) expired(s *Session, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return now.Sub(s.lastUsed) > st.idleTimeout
+}
+
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
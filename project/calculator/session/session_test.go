@@ -0,0 +1,50 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestSetVariableLimit(t *testing.T) {
+	s, err := NewStore(time.Minute, 1).Create("client")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < MaxVariables; i++ {
+		if err := s.SetVariable(fmt.Sprintf("v%d", i), float64(i)); err != nil {
+			t.Fatalf("SetVariable(v%d): %v", i, err)
+		}
+	}
+	if err := s.SetVariable("extra", 1); !errors.Is(err, ErrTooManyVariables) {
+		t.Errorf("SetVariable of variable %d = %v, want ErrTooManyVariables", MaxVariables+1, err)
+	}
+	// Existing variables can still be updated.
+	if err := s.SetVariable("v0", 42); err != nil {
+		t.Errorf("updating v0: %v", err)
+	}
+	if vars := s.Variables(); vars["v0"] != 42 || len(vars) != MaxVariables+2 {
+		t.Errorf("v0 = %v among %d variables, want 42 among %d", vars["v0"], len(vars), MaxVariables+2)
+	}
+}
+
+func TestStoreLimits(t *testing.T) {
+	st := NewStore(time.Minute, 2)
+	a, err := st.Create("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.Create("alice"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.Create("alice"); !errors.Is(err, ErrTooManySessions) {
+		t.Errorf("third session = %v, want ErrTooManySessions", err)
+	}
+	if _, err := st.Create("bob"); err != nil {
+		t.Errorf("bob's first session: %v", err)
+	}
+	if _, err := st.Get("bob", a.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("bob getting alice's session = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,196 @@
+// Package stats computes summary statistics over a stream of values in a
+// single pass and bounded memory.
+package stats
+
+import (
+	"math"
+	"sort"
+)
+
+// ExactLimit is how many values an Accumulator keeps to report exact
+// quantiles. Past it, quantiles are estimated with the P² algorithm.
+const ExactLimit = 10000
+
+// Accumulator collects values one at a time. Mean and variance use Welford's
+// method, which is numerically stable for long streams.
+type Accumulator struct {
+	count    int64
+	mean, m2 float64
+	min, max float64
+
+	exact      []float64 // nil once ExactLimit is passed
+	estimators map[float64]*p2
+}
+
+// NewAccumulator returns an Accumulator able to report the given quantiles,
+// each in [0, 1], in addition to the median.
+func NewAccumulator(quantiles []float64) *Accumulator {
+	a := &Accumulator{
+		exact:      make([]float64, 0, 64),
+		estimators: map[float64]*p2{0.5: newP2(0.5)},
+	}
+	for _, q := range quantiles {
+		if a.estimators[q] == nil {
+			a.estimators[q] = newP2(q)
+		}
+	}
+	return a
+}
+
+// Add records x.
+func (a *Accumulator) Add(x float64) {
+	a.count++
+	if a.count == 1 {
+		a.min, a.max = x, x
+	}
+	a.min = math.Min(a.min, x)
+	a.max = math.Max(a.max, x)
+
+	delta := x - a.mean
+	a.mean += delta / float64(a.count)
+	a.m2 += delta * (x - a.mean)
+
+	if a.exact != nil {
+		if len(a.exact) < ExactLimit {
+			a.exact = append(a.exact, x)
+		} else {
+			a.exact = nil
+		}
+	}
+	for _, e := range a.estimators {
+		e.add(x)
+	}
+}
+
+func (a *Accumulator) Count() int64    { return a.count }
+func (a *Accumulator) Mean() float64   { return a.mean }
+func (a *Accumulator) Min() float64    { return a.min }
+func (a *Accumulator) Max() float64    { return a.max }
+func (a *Accumulator) Median() float64 { return a.Quantile(0.5) }
+
+// Variance returns the sample variance, or 0 for fewer than two values.
+func (a *Accumulator) Variance() float64 {
+	if a.count < 2 {
+		return 0
+	}
+	return a.m2 / float64(a.count-1)
+}
+
+// StdDev returns the sample standard deviation.
+func (a *Accumulator) StdDev() float64 {
+	return math.Sqrt(a.Variance())
+}
+
+// Approximate reports whether quantiles are estimates rather than exact.
+func (a *Accumulator) Approximate() bool {
+	return a.exact == nil
+}
+
+// Quantile returns the q-th quantile, q in [0, 1]. It must be the median or
+// one of the quantiles passed to NewAccumulator once Approximate is true.
+func (a *Accumulator) Quantile(q float64) float64 {
+	switch {
+	case a.count == 0:
+		return math.NaN()
+	case q <= 0:
+		return a.min
+	case q >= 1:
+		return a.max
+	case a.exact != nil:
+		sort.Float64s(a.exact)
+		return interpolate(a.exact, q)
+	}
+	if e := a.estimators[q]; e != nil {
+		return e.value()
+	}
+	return math.NaN()
+}
+
+// interpolate returns the q-th quantile of the sorted values, interpolating
+// linearly between the closest ranks.
+func interpolate(sorted []float64, q float64) float64 {
+	pos := q * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	hi := int(math.Ceil(pos))
+	return sorted[lo] + (pos-float64(lo))*(sorted[hi]-sorted[lo])
+}
+
+// p2 estimates one quantile with the P² algorithm of Jain and Chlamtac, which
+// tracks five markers whose heights approximate the quantile and its
+// neighbourhood.
+type p2 struct {
+	p      float64
+	count  int
+	q      [5]float64 // marker heights
+	n      [5]int     // actual marker positions
+	want   [5]float64 // desired marker positions
+	stride [5]float64 // how far each desired position moves per value
+}
+
+func newP2(p float64) *p2 {
+	return &p2{p: p, stride: [5]float64{0, p / 2, p, (1 + p) / 2, 1}}
+}
+
+func (e *p2) add(x float64) {
+	if e.count < 5 {
+		e.q[e.count] = x
+		e.count++
+		if e.count == 5 {
+			sort.Float64s(e.q[:])
+			p := e.p
+			e.n = [5]int{1, 2, 3, 4, 5}
+			e.want = [5]float64{1, 1 + 2*p, 1 + 4*p, 3 + 2*p, 5}
+		}
+		return
+	}
+	e.count++
+
+	var k int
+	switch {
+	case x < e.q[0]:
+		e.q[0] = x
+		k = 0
+	case x >= e.q[4]:
+		e.q[4] = x
+		k = 3
+	default:
+		for k = 0; k < 3 && x >= e.q[k+1]; k++ {
+		}
+	}
+	for i := k + 1; i < 5; i++ {
+		e.n[i]++
+	}
+	for i := range e.want {
+		e.want[i] += e.stride[i]
+	}
+
+	for i := 1; i <= 3; i++ {
+		d := e.want[i] - float64(e.n[i])
+		if (d >= 1 && e.n[i+1]-e.n[i] > 1) || (d <= -1 && e.n[i-1]-e.n[i] < -1) {
+			s := 1
+			if d < 0 {
+				s = -1
+			}
+			if h := e.parabolic(i, float64(s)); e.q[i-1] < h && h < e.q[i+1] {
+				e.q[i] = h
+			} else {
+				e.q[i] += float64(s) * (e.q[i+s] - e.q[i]) / float64(e.n[i+s]-e.n[i])
+			}
+			e.n[i] += s
+		}
+	}
+}
+
+func (e *p2) parabolic(i int, d float64) float64 {
+	n0, n1, n2 := float64(e.n[i-1]), float64(e.n[i]), float64(e.n[i+1])
+	return e.q[i] + d/(n2-n0)*((n1-n0+d)*(e.q[i+1]-e.q[i])/(n2-n1)+(n2-n1-d)*(e.q[i]-e.q[i-1])/(n1-n0))
+}
+
+func (e *p2) value() float64 {
+	if e.count < 5 {
+		sorted := append([]float64(nil), e.q[:e.count]...)
+		sort.Float64s(sorted)
+		return interpolate(sorted, e.p)
+	}
+	return e.q[2]
+}
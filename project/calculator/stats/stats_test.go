@@ -0,0 +1,99 @@
+package stats
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestAccumulatorExact(t *testing.T) {
+	tests := []struct {
+		values               []float64
+		mean, variance       float64
+		min, max, median     float64
+		quantile, quantileAt float64
+	}{
+		{[]float64{4}, 4, 0, 4, 4, 4, 0.9, 4},
+		{[]float64{1, 2, 3, 4, 5}, 3, 2.5, 1, 5, 3, 0.25, 2},
+		{[]float64{5, 1, 4, 2}, 3, 10.0 / 3, 1, 5, 3, 0.9, 4.7},
+		{[]float64{2, 4, 4, 4, 5, 5, 7, 9}, 5, 32.0 / 7, 2, 9, 4.5, 0.75, 5.5},
+		{[]float64{-3, -3, -3}, -3, 0, -3, -3, -3, 0.1, -3},
+	}
+	for _, tt := range tests {
+		a := NewAccumulator([]float64{tt.quantile})
+		for _, x := range tt.values {
+			a.Add(x)
+		}
+		if a.Approximate() {
+			t.Errorf("%v: Approximate() = true", tt.values)
+		}
+		checks := []struct {
+			name      string
+			got, want float64
+		}{
+			{"Count", float64(a.Count()), float64(len(tt.values))},
+			{"Mean", a.Mean(), tt.mean},
+			{"Variance", a.Variance(), tt.variance},
+			{"Min", a.Min(), tt.min},
+			{"Max", a.Max(), tt.max},
+			{"Median", a.Median(), tt.median},
+			{"Quantile", a.Quantile(tt.quantile), tt.quantileAt},
+		}
+		for _, c := range checks {
+			if math.Abs(c.got-c.want) > 1e-12 {
+				t.Errorf("%v: %s = %v, want %v", tt.values, c.name, c.got, c.want)
+			}
+		}
+	}
+}
+
+func TestAccumulatorEmpty(t *testing.T) {
+	a := NewAccumulator(nil)
+	if a.Count() != 0 || a.Variance() != 0 || !math.IsNaN(a.Median()) {
+		t.Errorf("empty accumulator: count %d, variance %v, median %v", a.Count(), a.Variance(), a.Median())
+	}
+}
+
+// TestAccumulatorStable checks Welford's method on values whose mean dwarfs
+// their spread, where the naive sum of squares loses every digit.
+func TestAccumulatorStable(t *testing.T) {
+	a := NewAccumulator(nil)
+	for _, x := range []float64{1e9 + 4, 1e9 + 7, 1e9 + 13, 1e9 + 16} {
+		a.Add(x)
+	}
+	if a.Mean() != 1e9+10 || a.Variance() != 30 {
+		t.Errorf("mean %v, variance %v, want 1e9+10 and 30", a.Mean(), a.Variance())
+	}
+}
+
+func TestAccumulatorApproximate(t *testing.T) {
+	tests := []struct {
+		name   string
+		sample func(r *rand.Rand) float64
+		// quantile returns the exact q-th quantile of the distribution.
+		quantile func(q float64) float64
+	}{
+		{"uniform", func(r *rand.Rand) float64 { return r.Float64() * 100 }, func(q float64) float64 { return q * 100 }},
+		{"exponential", func(r *rand.Rand) float64 { return r.ExpFloat64() }, func(q float64) float64 { return -math.Log(1 - q) }},
+	}
+	quantiles := []float64{0.1, 0.9, 0.99}
+	for _, tt := range tests {
+		r := rand.New(rand.NewSource(1))
+		a := NewAccumulator(quantiles)
+		for i := 0; i < 10*ExactLimit; i++ {
+			a.Add(tt.sample(r))
+		}
+		if !a.Approximate() {
+			t.Errorf("%s: Approximate() = false after %d values", tt.name, a.Count())
+		}
+		for _, q := range append(quantiles, 0.5) {
+			got, want := a.Quantile(q), tt.quantile(q)
+			if math.Abs(got-want) > 0.05*math.Max(want, 1) {
+				t.Errorf("%s: Quantile(%v) = %v, want about %v", tt.name, q, got, want)
+			}
+		}
+		if got := a.Quantile(0.3); !math.IsNaN(got) {
+			t.Errorf("%s: Quantile(0.3) = %v for an untracked quantile, want NaN", tt.name, got)
+		}
+	}
+}
@@ -0,0 +1,352 @@
+// Package units converts between units and multiplies and divides them,
+// tracking the dimension of each so that incompatible quantities are caught.
+//
+// Units are defined in a Table. The default one, embedded from units.json,
+// covers length, mass, time, temperature and data sizes, and can be extended
+// with more JSON definitions at run time.
+package units
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The base dimensions, in the order they appear in a Dimension.
+var baseDimensions = [...]string{"length", "mass", "time", "temperature", "information"}
+
+// Dimension gives the power of each base dimension.
+type Dimension [len(baseDimensions)]int
+
+func (d Dimension) String() string {
+	var num, den []string
+	for i, p := range d {
+		switch {
+		case p == 1:
+			num = append(num, baseDimensions[i])
+		case p > 1:
+			num = append(num, fmt.Sprintf("%s^%d", baseDimensions[i], p))
+		case p == -1:
+			den = append(den, baseDimensions[i])
+		case p < -1:
+			den = append(den, fmt.Sprintf("%s^%d", baseDimensions[i], -p))
+		}
+	}
+	return formatFraction(num, den, "dimensionless")
+}
+
+// formatFraction writes num*num.../den/den..., or empty if there are no
+// terms at all.
+func formatFraction(num, den []string, empty string) string {
+	if len(num) == 0 && len(den) == 0 {
+		return empty
+	}
+	s := strings.Join(num, "*")
+	if s == "" {
+		s = "1"
+	}
+	for _, d := range den {
+		s += "/" + d
+	}
+	return s
+}
+
+// Unit is a named unit or a product of powers of named units. A value v in
+// the unit is v*Factor + Offset in the SI units of its dimension.
+type Unit struct {
+	Symbol    string
+	Name      string
+	Aliases   []string
+	Dimension Dimension
+	Factor    float64
+	Offset    float64
+
+	terms []term // sorted by symbol
+}
+
+type term struct {
+	symbol string
+	power  int
+}
+
+func (u *Unit) String() string { return u.Symbol }
+
+// UnknownUnitError reports a unit the table does not define.
+type UnknownUnitError struct {
+	Name string
+}
+
+func (e *UnknownUnitError) Error() string {
+	return fmt.Sprintf("unknown unit %q", e.Name)
+}
+
+// DimensionError reports units of different dimensions used together.
+type DimensionError struct {
+	From, To *Unit
+}
+
+func (e *DimensionError) Error() string {
+	return fmt.Sprintf("cannot convert %s (%v) to %s (%v)", e.From, e.From.Dimension, e.To, e.To.Dimension)
+}
+
+// ErrOffset is returned for arithmetic on units such as °C whose zero is not
+// the SI zero, where the meaning of the result would be ambiguous.
+var ErrOffset = errors.New("units with an offset zero, such as °C, cannot be combined; convert to the SI unit first")
+
+// Convert returns the value v in from expressed in to.
+func Convert(v float64, from, to *Unit) (float64, error) {
+	if from.Dimension != to.Dimension {
+		return 0, &DimensionError{From: from, To: to}
+	}
+	return (v*from.Factor + from.Offset - to.Offset) / to.Factor, nil
+}
+
+// Mul returns the unit a*b.
+func Mul(a, b *Unit) (*Unit, error) {
+	return combine(a, b, 1)
+}
+
+// Quo returns the unit a/b.
+func Quo(a, b *Unit) (*Unit, error) {
+	return combine(a, b, -1)
+}
+
+func combine(a, b *Unit, sign int) (*Unit, error) {
+	if a.Offset != 0 || b.Offset != 0 {
+		return nil, ErrOffset
+	}
+	powers := make(map[string]int)
+	for _, t := range a.terms {
+		powers[t.symbol] += t.power
+	}
+	for _, t := range b.terms {
+		powers[t.symbol] += sign * t.power
+	}
+	u := &Unit{Factor: a.Factor * math.Pow(b.Factor, float64(sign))}
+	for i := range u.Dimension {
+		u.Dimension[i] = a.Dimension[i] + sign*b.Dimension[i]
+	}
+	for symbol, p := range powers {
+		if p != 0 {
+			u.terms = append(u.terms, term{symbol, p})
+		}
+	}
+	u.setSymbol()
+	return u, nil
+}
+
+// setSymbol derives the symbol of a compound unit from its terms.
+func (u *Unit) setSymbol() {
+	sort.Slice(u.terms, func(i, j int) bool { return u.terms[i].symbol < u.terms[j].symbol })
+	var num, den []string
+	for _, t := range u.terms {
+		switch {
+		case t.power == 1:
+			num = append(num, t.symbol)
+		case t.power > 1:
+			num = append(num, fmt.Sprintf("%s^%d", t.symbol, t.power))
+		case t.power == -1:
+			den = append(den, t.symbol)
+		default:
+			den = append(den, fmt.Sprintf("%s^%d", t.symbol, -t.power))
+		}
+	}
+	u.Symbol = formatFraction(num, den, "1")
+}
+
+// Table is a set of units looked up by symbol, name or alias.
+type Table struct {
+	units  []*Unit
+	lookup map[string]*Unit
+}
+
+//go:embed units.json
+var defaultUnits string
+
+// Default returns a new table of the built-in units.
+func Default() *Table {
+	t := &Table{lookup: make(map[string]*Unit)}
+	if err := t.Load(strings.NewReader(defaultUnits)); err != nil {
+		panic("units: bad built-in table: " + err.Error())
+	}
+	return t
+}
+
+// definition is a unit as written in a JSON table. A unit is defined either
+// by its dimension, as the SI unit of it, or as a factor of an expression of
+// units defined before it, plus an optional offset in SI units.
+type definition struct {
+	Symbol     string         `json:"symbol"`
+	Name       string         `json:"name"`
+	Aliases    []string       `json:"aliases"`
+	Dimension  map[string]int `json:"dimension"`
+	Definition string         `json:"definition"`
+	Factor     float64        `json:"factor"`
+	Offset     float64        `json:"offset"`
+}
+
+// Load adds the units defined in a JSON array read from r. Symbols, names
+// and aliases must not clash with units already in the table. On error, no
+// unit is added.
+func (t *Table) Load(r io.Reader) error {
+	var defs []definition
+	if err := json.NewDecoder(r).Decode(&defs); err != nil {
+		return err
+	}
+
+	staged := &Table{units: append([]*Unit(nil), t.units...), lookup: make(map[string]*Unit, len(t.lookup))}
+	for k, u := range t.lookup {
+		staged.lookup[k] = u
+	}
+	for i, def := range defs {
+		if err := staged.add(def); err != nil {
+			return fmt.Errorf("unit %d (%q): %w", i, def.Symbol, err)
+		}
+	}
+	*t = *staged
+	return nil
+}
+
+func (t *Table) add(def definition) error {
+	if def.Symbol == "" {
+		return errors.New("a symbol is required")
+	}
+	if strings.ContainsAny(def.Symbol, "*/^ ") || def.Symbol == "1" {
+		return errors.New("a symbol cannot contain *, /, ^ or spaces, nor be 1")
+	}
+	factor := def.Factor
+	if factor == 0 {
+		factor = 1
+	}
+	if factor < 0 || math.IsInf(factor, 0) || math.IsNaN(factor) {
+		return fmt.Errorf("factor must be a positive number, got %v", factor)
+	}
+
+	u := &Unit{Symbol: def.Symbol, Name: def.Name, Aliases: def.Aliases, Factor: factor, Offset: def.Offset}
+	switch {
+	case def.Definition != "" && def.Dimension != nil:
+		return errors.New("only one of dimension and definition may be given")
+	case def.Definition != "":
+		base, err := t.Parse(def.Definition)
+		if err != nil {
+			return err
+		}
+		if base.Offset != 0 {
+			return fmt.Errorf("cannot be defined in terms of %s, which has an offset", base)
+		}
+		u.Dimension = base.Dimension
+		u.Factor *= base.Factor
+	case def.Dimension != nil:
+		for name, p := range def.Dimension {
+			i := dimensionIndex(name)
+			if i < 0 {
+				return fmt.Errorf("unknown dimension %q; known ones are %s", name, strings.Join(baseDimensions[:], ", "))
+			}
+			u.Dimension[i] = p
+		}
+	default:
+		return errors.New("a dimension or a definition is required")
+	}
+	u.terms = []term{{def.Symbol, 1}}
+
+	for _, key := range append([]string{def.Symbol, def.Name}, def.Aliases...) {
+		if key == "" {
+			continue
+		}
+		if existing := t.lookup[key]; existing != nil && existing != u {
+			return fmt.Errorf("%q is already used by %s", key, existing.Symbol)
+		}
+		t.lookup[key] = u
+	}
+	t.units = append(t.units, u)
+	return nil
+}
+
+func dimensionIndex(name string) int {
+	for i, n := range baseDimensions {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Units returns the named units in the order they were defined.
+func (t *Table) Units() []*Unit {
+	return append([]*Unit(nil), t.units...)
+}
+
+// Parse reads a unit expression: unit symbols, names or aliases, each with
+// an optional integer power, separated by * or /, such as "kg*m/s^2". The
+// empty expression and "1" are dimensionless.
+func (t *Table) Parse(expr string) (*Unit, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" || expr == "1" {
+		return &Unit{Symbol: "1", Factor: 1}, nil
+	}
+
+	result := &Unit{Factor: 1}
+	for i, sign := 0, 1; ; {
+		end := strings.IndexAny(expr[i:], "*/")
+		if end < 0 {
+			end = len(expr)
+		} else {
+			end += i
+		}
+		u, err := t.parseTerm(strings.TrimSpace(expr[i:end]))
+		if err != nil {
+			return nil, err
+		}
+		if u.Offset != 0 && !(i == 0 && end == len(expr)) {
+			return nil, ErrOffset
+		}
+		if i == 0 && end == len(expr) {
+			return u, nil
+		}
+		if result, err = combine(result, u, sign); err != nil {
+			return nil, err
+		}
+		if end == len(expr) {
+			return result, nil
+		}
+		sign = 1
+		if expr[end] == '/' {
+			sign = -1
+		}
+		i = end + 1
+	}
+}
+
+// parseTerm reads a unit with an optional power, such as "s^2".
+func (t *Table) parseTerm(s string) (*Unit, error) {
+	name, power := s, 1
+	if i := strings.IndexByte(s, '^'); i >= 0 {
+		p, err := strconv.Atoi(strings.TrimSpace(s[i+1:]))
+		if err != nil || p == 0 {
+			return nil, fmt.Errorf("invalid power in %q", s)
+		}
+		name, power = strings.TrimSpace(s[:i]), p
+	}
+	u := t.lookup[name]
+	if u == nil {
+		return nil, &UnknownUnitError{Name: name}
+	}
+	if power == 1 {
+		return u, nil
+	}
+	if u.Offset != 0 {
+		return nil, ErrOffset
+	}
+	powered := &Unit{Factor: math.Pow(u.Factor, float64(power)), terms: []term{{u.Symbol, power}}}
+	for i, p := range u.Dimension {
+		powered.Dimension[i] = p * power
+	}
+	powered.setSymbol()
+	return powered, nil
+}
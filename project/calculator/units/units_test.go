@@ -0,0 +1,199 @@
+package units
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func mustParse(t *testing.T, table *Table, expr string) *Unit {
+	t.Helper()
+	u, err := table.Parse(expr)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", expr, err)
+	}
+	return u
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in        string
+		symbol    string
+		factor    float64
+		dimension Dimension
+	}{
+		{"", "1", 1, Dimension{}},
+		{"1", "1", 1, Dimension{}},
+		{"km", "km", 1000, Dimension{1}},
+		{"meters", "m", 1, Dimension{1}},
+		{"feet", "ft", 0.3048, Dimension{1}},
+		{"s^2", "s^2", 1, Dimension{0, 0, 2}},
+		{"m/s", "m/s", 1, Dimension{1, 0, -1}},
+		{"kg*m/s^2", "kg*m/s^2", 1, Dimension{1, 1, -2}},
+		{"km / h", "km/h", 1000.0 / 3600, Dimension{1, 0, -1}},
+		{"m*m/m", "m", 1, Dimension{1}},
+		{"km^-1", "1/km", 0.001, Dimension{-1}},
+		{"MiB", "MiB", 8 << 20, Dimension{0, 0, 0, 0, 1}},
+		{"m/m", "1", 1, Dimension{}},
+	}
+	table := Default()
+	for _, tt := range tests {
+		u := mustParse(t, table, tt.in)
+		if u.Symbol != tt.symbol || math.Abs(u.Factor-tt.factor) > 1e-12*tt.factor || u.Dimension != tt.dimension {
+			t.Errorf("Parse(%q) = %s, factor %v, %v; want %s, factor %v, %v",
+				tt.in, u.Symbol, u.Factor, u.Dimension, tt.symbol, tt.factor, tt.dimension)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		in      string
+		unknown string // the unit of an UnknownUnitError
+		offset  bool   // whether the error is ErrOffset
+	}{
+		{"furlong", "furlong", false},
+		{"m*parsec", "parsec", false},
+		{"m^", "", false},
+		{"m^0", "", false},
+		{"m^x", "", false},
+		{"°C^2", "", true},
+		{"°C/s", "", true},
+		{"m*°F", "", true},
+	}
+	table := Default()
+	for _, tt := range tests {
+		_, err := table.Parse(tt.in)
+		var unknown *UnknownUnitError
+		switch {
+		case err == nil:
+			t.Errorf("Parse(%q) succeeded, want an error", tt.in)
+		case tt.unknown != "" && (!errors.As(err, &unknown) || unknown.Name != tt.unknown):
+			t.Errorf("Parse(%q) = %v, want unknown unit %q", tt.in, err, tt.unknown)
+		case tt.offset != errors.Is(err, ErrOffset):
+			t.Errorf("Parse(%q) = %v, want ErrOffset %v", tt.in, err, tt.offset)
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		v        float64
+		from, to string
+		want     float64
+	}{
+		{1, "km", "m", 1000},
+		{1, "mi", "km", 1.609344},
+		{90, "km/h", "m/s", 25},
+		{100, "°C", "°F", 212},
+		{-40, "°F", "°C", -40},
+		{0, "K", "°C", -273.15},
+		{1, "GiB", "MB", 1073.741824},
+		{1, "wk", "min", 10080},
+		{3, "N", "kg*m/s^2", 3},
+	}
+	table := Default()
+	if err := table.Load(strings.NewReader(`[{"symbol": "N", "name": "newton", "definition": "kg*m/s^2"}]`)); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		got, err := Convert(tt.v, mustParse(t, table, tt.from), mustParse(t, table, tt.to))
+		if err != nil || math.Abs(got-tt.want) > 1e-9*math.Max(1, math.Abs(tt.want)) {
+			t.Errorf("Convert(%v %s, %s) = %v, %v, want %v", tt.v, tt.from, tt.to, got, err, tt.want)
+		}
+	}
+}
+
+func TestConvertDimensionMismatch(t *testing.T) {
+	table := Default()
+	for _, pair := range [][2]string{{"m", "s"}, {"m/s", "m/s^2"}, {"kg", "1"}, {"°C", "m"}} {
+		_, err := Convert(1, mustParse(t, table, pair[0]), mustParse(t, table, pair[1]))
+		var dimErr *DimensionError
+		if !errors.As(err, &dimErr) {
+			t.Errorf("Convert(%s, %s) = %v, want a DimensionError", pair[0], pair[1], err)
+		}
+	}
+}
+
+// TestConvertOverflow documents that large powers overflow the factor; the
+// server rejects the non-finite result.
+func TestConvertOverflow(t *testing.T) {
+	table := Default()
+	got, err := Convert(1, mustParse(t, table, "km^400"), mustParse(t, table, "m^400"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !math.IsInf(got, 0) && !math.IsNaN(got) {
+		t.Errorf("Convert(1 km^400, m^400) = %v, want a non-finite value", got)
+	}
+}
+
+func TestMulQuo(t *testing.T) {
+	table := Default()
+	u, err := Mul(mustParse(t, table, "km"), mustParse(t, table, "km"))
+	if err != nil || u.Symbol != "km^2" || u.Factor != 1e6 || u.Dimension != (Dimension{2}) {
+		t.Errorf("km*km = %v (factor %v, %v), %v", u, u.Factor, u.Dimension, err)
+	}
+	u, err = Quo(mustParse(t, table, "m"), mustParse(t, table, "s"))
+	if err != nil || u.Symbol != "m/s" {
+		t.Errorf("m/s = %v, %v", u, err)
+	}
+	if _, err := Mul(mustParse(t, table, "°C"), mustParse(t, table, "m")); !errors.Is(err, ErrOffset) {
+		t.Errorf("°C*m = %v, want ErrOffset", err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	table := Default()
+	err := table.Load(strings.NewReader(`[
+		{"symbol": "fur", "name": "furlong", "aliases": ["furlongs"], "definition": "yd", "factor": 220},
+		{"symbol": "ftn", "name": "fortnight", "definition": "d", "factor": 14},
+		{"symbol": "cd", "name": "candela", "dimension": {}}
+	]`))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	got, err := Convert(1, mustParse(t, table, "furlongs/ftn"), mustParse(t, table, "m/s"))
+	if want := 201.168 / (14 * 86400); err != nil || math.Abs(got-want) > 1e-15 {
+		t.Errorf("1 furlong per fortnight = %v m/s, %v, want %v", got, err, want)
+	}
+	units := table.Units()
+	if last := units[len(units)-1]; last.Symbol != "cd" {
+		t.Errorf("the last unit is %s, want cd", last)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name, json, msg string
+	}{
+		{"not JSON", `{`, "unexpected EOF"},
+		{"no symbol", `[{"name": "x", "definition": "m"}]`, "a symbol is required"},
+		{"compound symbol", `[{"symbol": "m/s", "definition": "m"}]`, "cannot contain"},
+		{"clash", `[{"symbol": "metre", "definition": "m"}]`, `"metre" is already used by m`},
+		{"alias clash", `[{"symbol": "x", "aliases": ["s"], "definition": "m"}]`, `"s" is already used by s`},
+		{"negative factor", `[{"symbol": "x", "definition": "m", "factor": -1}]`, "positive"},
+		{"both", `[{"symbol": "x", "definition": "m", "dimension": {"length": 1}}]`, "only one of"},
+		{"neither", `[{"symbol": "x"}]`, "dimension or a definition"},
+		{"bad dimension", `[{"symbol": "x", "dimension": {"charge": 1}}]`, `unknown dimension "charge"`},
+		{"unknown base", `[{"symbol": "x", "definition": "parsec"}]`, "unknown unit"},
+		{"offset base", `[{"symbol": "x", "definition": "°C"}]`, "has an offset"},
+		// The first unit is valid but the second fails, so neither is added.
+		{"atomic", `[{"symbol": "x", "definition": "m"}, {"symbol": "y"}]`, "unit 1"},
+	}
+	for _, tt := range tests {
+		table := Default()
+		before := len(table.Units())
+		err := table.Load(strings.NewReader(tt.json))
+		if err == nil || !strings.Contains(err.Error(), tt.msg) {
+			t.Errorf("%s: Load = %v, want an error containing %q", tt.name, err, tt.msg)
+		}
+		if after := len(table.Units()); after != before {
+			t.Errorf("%s: %d units added by a failed Load", tt.name, after-before)
+		}
+		if _, err := table.Parse("x"); err == nil {
+			t.Errorf("%s: x was defined by a failed Load", tt.name)
+		}
+	}
+}
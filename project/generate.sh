@@ -1,3 +1,9 @@
 protoc greet/greetpb/greet.proto --go_out=plugins=grpc:.
 
 protoc calculator/calculatorpb/calculator.proto --go_out=plugins=grpc:.
+
+protoc calculator/calculatorpb/matrix.proto --go_out=plugins=grpc:.
+
+protoc calculator/calculatorpb/operations.proto --go_out=plugins=grpc:.
+
+protoc calculator/calculatorpb/units.proto --go_out=plugins=grpc:.
@@ -1,228 +1,356 @@
 package main
 
 import (
+	"bufio"
 	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"github.com/grpc-project02/project/greet/greetpb"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/status"
 	"io"
 	"log"
+	"os"
+	"strings"
 	"sync"
 	"time"
 )
 
+// newCall returns the context of a single call, bounded by -deadline.
+type newCall func() (context.Context, context.CancelFunc)
+
+// commands maps each subcommand to the RPC mode it exercises.
+var commands = map[string]func(call newCall, c greetpb.GreetServiceClient, names []string, lastName string) error{
+	"unary":         doUnary,
+	"server-stream": serverStream,
+	"client-stream": clientStream,
+	"bidi":          doBiDiStream,
+	"deadline":      doUnaryWithDeadline,
+	"stats":         getGreetingStats,
+}
+
+const usage = `usage: client <command> [flags]
+
+commands:
+  unary          call Greet once per name
+  server-stream  call GreetManyTimes once per name
+  client-stream  send every name to LongGreet
+  bidi           send every name to GreetEveryone
+  deadline       call GreetWithDeadline once per name
+  stats          call GetGreetingStats once per name
+
+Names are read one per line from stdin when -names is empty.
+
+flags:
+`
+
 func main() {
 	fmt.Println("Hello I'm a client")
-	certFile := "ssl/ca.crt"
-	creds, err := credentials.NewClientTLSFromFile(certFile, "")
+
+	if len(os.Args) < 2 || commands[os.Args[1]] == nil {
+		fmt.Fprint(os.Stderr, usage)
+		newFlagSet("").PrintDefaults()
+		os.Exit(2)
+	}
+	command := os.Args[1]
+
+	fs := newFlagSet(command)
+	addr := fs.String("addr", "localhost:50051", "server address")
+	caFile := fs.String("ca", "ssl/ca.crt", "CA certificate used to verify the server")
+	plaintext := fs.Bool("insecure", false, "connect without TLS")
+	namesFlag := fs.String("names", "", "comma-separated first names (read from stdin when empty)")
+	lastName := fs.String("last_name", "", "last name sent with every greeting")
+	repeat := fs.Int("repeat", 1, "number of times to run the command")
+	deadline := fs.Duration("deadline", 0, "deadline for each call (0 means none; deadline command defaults to 5s)")
+	fs.Parse(os.Args[2:])
+
+	if *repeat < 1 {
+		log.Fatalf("-repeat must be at least 1, got %d", *repeat)
+	}
+	if command == "deadline" && *deadline == 0 {
+		*deadline = 5 * time.Second
+	}
+
+	names, err := readNames(*namesFlag, os.Stdin)
 	if err != nil {
-		log.Fatalf("Error while loading CA trust certificate: %v", err)
-		return
+		log.Fatalf("Error while reading names: %v", err)
 	}
-	opts := grpc.WithTransportCredentials(creds)
-	conn, err := grpc.Dial("localhost:50051", opts)
-	defer conn.Close()
+	if len(names) == 0 {
+		log.Fatalln("No names to greet")
+	}
+
+	var opts grpc.DialOption
+	if *plaintext {
+		opts = grpc.WithTransportCredentials(insecure.NewCredentials())
+	} else {
+		creds, err := credentials.NewClientTLSFromFile(*caFile, "")
+		if err != nil {
+			log.Fatalf("Error while loading CA trust certificate: %v", err)
+		}
+		opts = grpc.WithTransportCredentials(creds)
+	}
+	conn, err := grpc.Dial(*addr, opts)
 	if err != nil {
 		log.Fatalf("could not connect: %v", err)
 	}
+	defer conn.Close()
 
 	c := greetpb.NewGreetServiceClient(conn)
-	// log.Printf("Created client: %f", c)
-	doUnary(c)
-	//serverStream(c)
-	//clientStream(c)
-	//doBiDiStream(c)
-	//doUnaryWithDeadline(c, 1*time.Second)
-	//doUnaryWithDeadline(c, 5*time.Second)
+	call := func() (context.Context, context.CancelFunc) { return callContext(*deadline) }
+	for i := 0; i < *repeat; i++ {
+		if err := commands[command](call, c, names, *lastName); err != nil {
+			logViolations(err)
+			log.Fatalf("%s failed: %v", command, err)
+		}
+	}
 }
 
-func doUnaryWithDeadline(c greetpb.GreetServiceClient, timeout time.Duration) {
-	log.Println("Starting to do a UnaryWithDeadline RPC..")
-	req := &greetpb.GreetWithDeadlineRequest{
-		Greeting: &greetpb.Greeting{
-			FirstName: "Jinsu",
-			LastName:  "Sang",
-		},
+// logViolations prints the field violations the server attached to an
+// InvalidArgument error, if any.
+func logViolations(err error) {
+	st, ok := status.FromError(errors.Unwrap(err))
+	if !ok {
+		return
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
-	response, err := c.GreetWithDeadline(ctx, req)
-	if err != nil {
-		statusErr, ok := status.FromError(err)
-		if ok {
-			if statusErr.Code() == codes.DeadlineExceeded {
-				log.Println("Timeout was hit! Deadline was exceeded")
-			} else {
-				log.Printf("unexpected error %v", statusErr)
+	for _, detail := range st.Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			for _, v := range badRequest.GetFieldViolations() {
+				log.Printf("invalid %s: %s", v.GetField(), v.GetDescription())
 			}
-			return
-		} else {
-			log.Fatalf("error while calling GreetWithDeadline RPC: %v\n", err)
 		}
 	}
-	log.Printf("Response from GreetWithDeadline: %v\n", response.GetResult())
 }
 
-func doBiDiStream(c greetpb.GreetServiceClient) {
-	log.Println("Starting to do a BiDi Streaming RPC...")
+func newFlagSet(command string) *flag.FlagSet {
+	fs := flag.NewFlagSet(command, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
 
-	requests := []*greetpb.GreetEveryoneRequest{
-		{
-			Greeting: &greetpb.Greeting{
-				FirstName: "Jinsu",
-			},
-		},
-		{
-			Greeting: &greetpb.Greeting{
-				FirstName: "Wanhee",
-			},
-		},
-		{
-			Greeting: &greetpb.Greeting{
-				FirstName: "Seongho",
-			},
-		},
-		{
+// deadlineExceeded reports whether err is a call hitting its deadline, which
+// the per-name commands log and move past.
+func deadlineExceeded(err error) bool {
+	if status.Code(err) != codes.DeadlineExceeded {
+		return false
+	}
+	log.Println("Timeout was hit! Deadline was exceeded")
+	return true
+}
+
+// callContext bounds a call by deadline, or leaves it unbounded when zero.
+func callContext(deadline time.Duration) (context.Context, context.CancelFunc) {
+	if deadline > 0 {
+		return context.WithTimeout(context.Background(), deadline)
+	}
+	return context.WithCancel(context.Background())
+}
+
+// readNames splits the -names flag, falling back to one name per line of r.
+func readNames(flagValue string, r io.Reader) ([]string, error) {
+	var names []string
+	if flagValue != "" {
+		for _, name := range strings.Split(flagValue, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
+		}
+		return names, nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if name := strings.TrimSpace(scanner.Text()); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names, scanner.Err()
+}
+
+func getGreetingStats(call newCall, c greetpb.GreetServiceClient, names []string, lastName string) error {
+	log.Println("Starting to do a GetGreetingStats RPC..")
+	for _, name := range names {
+		req := &greetpb.GetGreetingStatsRequest{
 			Greeting: &greetpb.Greeting{
-				FirstName: "Hana",
+				FirstName: name,
+				LastName:  lastName,
 			},
-		},
-		{
+		}
+		ctx, cancel := call()
+		response, err := c.GetGreetingStats(ctx, req)
+		cancel()
+		if deadlineExceeded(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("error while calling GetGreetingStats RPC: %w", err)
+		}
+		for _, stats := range response.GetStats() {
+			log.Printf("%s %s: %d visits, last seen %v", stats.GetGreeting().GetFirstName(), stats.GetGreeting().GetLastName(),
+				stats.GetVisits(), stats.GetLastSeen().AsTime().Local())
+		}
+	}
+	return nil
+}
+
+func doUnaryWithDeadline(call newCall, c greetpb.GreetServiceClient, names []string, lastName string) error {
+	log.Println("Starting to do a UnaryWithDeadline RPC..")
+	for _, name := range names {
+		req := &greetpb.GreetWithDeadlineRequest{
 			Greeting: &greetpb.Greeting{
-				FirstName: "Hyeonbin",
+				FirstName: name,
+				LastName:  lastName,
 			},
-		},
+		}
+		ctx, cancel := call()
+		response, err := c.GreetWithDeadline(ctx, req)
+		cancel()
+		if deadlineExceeded(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("error while calling GreetWithDeadline RPC: %w", err)
+		}
+		log.Printf("Response from GreetWithDeadline: %v\n", response.GetResult())
 	}
+	return nil
+}
 
-	stream, err := c.GreetEveryone(context.Background())
+func doBiDiStream(call newCall, c greetpb.GreetServiceClient, names []string, lastName string) error {
+	log.Println("Starting to do a BiDi Streaming RPC...")
+
+	ctx, cancel := call()
+	defer cancel()
+	stream, err := c.GreetEveryone(ctx)
 	if err != nil {
-		log.Fatalf("Error while creating stream")
-		return
+		return fmt.Errorf("error while creating stream: %w", err)
 	}
 
 	var wg sync.WaitGroup
-	for _, request := range requests {
-		wg.Add(1)
-		go func(request *greetpb.GreetEveryoneRequest) {
-			defer wg.Done()
-			log.Printf("Sending message %v\n", request)
-			stream.Send(request)
-		}(request)
-	}
-
-	wg.Wait()
-	stream.CloseSend()
-
-	results := make(chan string)
+	wg.Add(1)
 	go func() {
-		defer close(results)
-		for {
-			recv, err := stream.Recv()
-			if err == io.EOF {
-				break
+		defer wg.Done()
+		for _, name := range names {
+			request := &greetpb.GreetEveryoneRequest{
+				Greeting: &greetpb.Greeting{
+					FirstName: name,
+					LastName:  lastName,
+				},
 			}
-			if err != nil {
-				log.Fatalf("Error while receiving %v", err)
+			log.Printf("Sending message %v\n", request)
+			if err := stream.Send(request); err != nil {
+				log.Printf("Error while sending %v", err)
+				break
 			}
-			results <- recv.GetResult()
-
 		}
+		stream.CloseSend()
 	}()
 
-	for result := range results {
-		log.Printf("Received %v", result)
+	for {
+		recv, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("error while receiving: %w", err)
+		}
+		log.Printf("Received %v", recv.GetResult())
 	}
+	wg.Wait()
+	return nil
 }
 
-func clientStream(c greetpb.GreetServiceClient) {
+func clientStream(call newCall, c greetpb.GreetServiceClient, names []string, lastName string) error {
 	log.Println("Starting to do a Client Streaming RPC...")
 
-	requests := []*greetpb.LongGreetRequest{
-		{
-			Greeting: &greetpb.Greeting{
-				FirstName: "Jinsu",
-			},
-		},
-		{
-			Greeting: &greetpb.Greeting{
-				FirstName: "Wanhee",
-			},
-		},
-		{
-			Greeting: &greetpb.Greeting{
-				FirstName: "Seongho",
-			},
-		},
-		{
-			Greeting: &greetpb.Greeting{
-				FirstName: "Hana",
-			},
-		},
-		{
-			Greeting: &greetpb.Greeting{
-				FirstName: "Hyeonbin",
-			},
-		},
-	}
-
-	stream, err := c.LongGreet(context.Background())
+	ctx, cancel := call()
+	defer cancel()
+	stream, err := c.LongGreet(ctx)
 	if err != nil {
-		log.Fatalf("error while calling LongGreet: %v", err)
+		return fmt.Errorf("error while calling LongGreet: %w", err)
 	}
 
-	// we iterate over our slice and send each message individually
-	for _, request := range requests {
-		err := stream.Send(request)
+	// we iterate over the names and send each message individually
+	for _, name := range names {
+		err := stream.Send(&greetpb.LongGreetRequest{
+			Greeting: &greetpb.Greeting{
+				FirstName: name,
+				LastName:  lastName,
+			},
+		})
 		if err != nil {
-			log.Fatalln("error while sending request")
+			// the server closed the stream; CloseAndRecv reports why
+			break
 		}
 	}
 
 	response, err := stream.CloseAndRecv()
 	if err != nil {
-		log.Fatalf("error while receiving response from LongGreet: %v", err)
+		return fmt.Errorf("error while receiving response from LongGreet: %w", err)
 	}
 
 	log.Printf("LongGreet Response: %v\n", response)
+	return nil
 }
 
-func serverStream(c greetpb.GreetServiceClient) {
+func serverStream(call newCall, c greetpb.GreetServiceClient, names []string, lastName string) error {
 	log.Println("Starting to do a Server Streaming RPC...")
-	req := &greetpb.GreetManyTimesRequest{Greeting: &greetpb.Greeting{
-		FirstName: "Jinsu",
-		LastName:  "Sang",
-	}}
-	resStream, err := c.GreetManyTimes(context.Background(), req)
+	for _, name := range names {
+		req := &greetpb.GreetManyTimesRequest{Greeting: &greetpb.Greeting{
+			FirstName: name,
+			LastName:  lastName,
+		}}
+		if err := greetManyTimes(call, c, req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// greetManyTimes reads the whole GreetManyTimes stream for one request.
+func greetManyTimes(call newCall, c greetpb.GreetServiceClient, req *greetpb.GreetManyTimesRequest) error {
+	ctx, cancel := call()
+	defer cancel()
+	resStream, err := c.GreetManyTimes(ctx, req)
 	if err != nil {
-		log.Fatalf("Error while calling GreetManyTimes RPC: %v", err)
+		return fmt.Errorf("error while calling GreetManyTimes RPC: %w", err)
 	}
 
 	for {
 		response, err := resStream.Recv()
 		if err == io.EOF {
-			break
+			return nil
 		}
 		if err != nil {
-			log.Fatalf("error while reading stream: %v", err)
+			return fmt.Errorf("error while reading stream: %w", err)
 		}
 		log.Printf("Response from GreetManyTimes: %v", response.GetResult())
 	}
 }
 
-func doUnary(c greetpb.GreetServiceClient) {
+func doUnary(call newCall, c greetpb.GreetServiceClient, names []string, lastName string) error {
 	log.Println("Starting to do a Unary RPC..")
-	req := &greetpb.GreetRequest{
-		Greeting: &greetpb.Greeting{
-			FirstName: "Jinsu",
-			LastName:  "Sang",
-		},
-	}
-	response, err := c.Greet(context.Background(), req)
-	if err != nil {
-		log.Fatalf("error while calling Greet RPC: %v\n", err)
+	for _, name := range names {
+		req := &greetpb.GreetRequest{
+			Greeting: &greetpb.Greeting{
+				FirstName: name,
+				LastName:  lastName,
+			},
+		}
+		ctx, cancel := call()
+		response, err := c.Greet(ctx, req)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("error while calling Greet RPC: %w", err)
+		}
+		log.Printf("Response from Greet: %v\n", response.GetResult())
 	}
-	log.Printf("Response from Greet: %v\n", response.GetResult())
+	return nil
 }
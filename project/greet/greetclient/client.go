@@ -0,0 +1,259 @@
+// Package greetclient is a client library for the greet server. It wraps
+// the generated stubs with plain Go signatures:
+//
+//	c, err := greetclient.Dial("localhost:50051", greetclient.WithCAFile("ssl/ca.crt"))
+//	if err != nil {
+//		return err
+//	}
+//	defer c.Close()
+//	greeting, err := c.Greet(ctx, greetclient.Person{FirstName: "Ada", LastName: "Lovelace"})
+//
+// Connections, timeouts and retries behave as in the calculator client
+// library. Every greeting counts as a visit, so only GetGreetingStats is
+// retried. Errors from the server are gRPC status errors.
+package greetclient
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/grpc-project02/project/greet/greetpb"
+	"github.com/grpc-project02/project/internal/clientconn"
+	"google.golang.org/grpc"
+)
+
+// Option configures Dial.
+type Option = clientconn.Option
+
+// Options for Dial, shared with the calculator client library.
+var (
+	// WithInsecure connects without TLS.
+	WithInsecure = clientconn.WithInsecure
+	// WithCAFile verifies the server against the CA certificate in a PEM file.
+	WithCAFile = clientconn.WithCAFile
+	// WithTLSConfig connects with the given TLS configuration.
+	WithTLSConfig = clientconn.WithTLSConfig
+	// WithTimeout sets the timeout of unary calls; zero disables it.
+	WithTimeout = clientconn.WithTimeout
+	// WithRetries sets how many attempts are made and the first backoff.
+	WithRetries = clientconn.WithRetries
+	// WithDialOptions passes extra options to grpc.Dial.
+	WithDialOptions = clientconn.WithDialOptions
+)
+
+// Person is someone to greet.
+type Person struct {
+	FirstName string
+	LastName  string
+}
+
+func (p Person) greeting() *greetpb.Greeting {
+	return &greetpb.Greeting{FirstName: p.FirstName, LastName: p.LastName}
+}
+
+// Stats counts the greetings of a person.
+type Stats struct {
+	Person    Person
+	Visits    int64
+	FirstSeen time.Time
+	LastSeen  time.Time
+}
+
+// Client calls the greet server. It is safe for concurrent use.
+type Client struct {
+	conn  *grpc.ClientConn // nil if not owned
+	greet greetpb.GreetServiceClient
+}
+
+// once lists the methods that record a visit, which are not retried.
+var once = []string{
+	"greet.GreetService/Greet",
+	"greet.GreetService/GreetManyTimes",
+	"greet.GreetService/LongGreet",
+	"greet.GreetService/GreetEveryone",
+	"greet.GreetService/GreetWithDeadline",
+}
+
+// Dial connects to the greet server at target, such as "localhost:50051".
+func Dial(target string, opts ...Option) (*Client, error) {
+	conn, err := clientconn.Dial(target, []string{"greet.GreetService"}, once, opts...)
+	if err != nil {
+		return nil, err
+	}
+	c := New(conn)
+	c.conn = conn
+	return c, nil
+}
+
+// New returns a client using an existing connection, which Close leaves
+// open.
+func New(conn grpc.ClientConnInterface) *Client {
+	return &Client{greet: greetpb.NewGreetServiceClient(conn)}
+}
+
+// Close closes the connection opened by Dial.
+func (c *Client) Close() error {
+	if c.conn == nil {
+		return nil
+	}
+	return c.conn.Close()
+}
+
+// Greet returns a greeting for p.
+func (c *Client) Greet(ctx context.Context, p Person) (string, error) {
+	res, err := c.greet.Greet(ctx, &greetpb.GreetRequest{Greeting: p.greeting()})
+	if err != nil {
+		return "", err
+	}
+	return res.GetResult(), nil
+}
+
+// GreetWithDeadline returns a greeting for p from the server's slow path,
+// which takes a few seconds. Give ctx a deadline to bound the wait.
+func (c *Client) GreetWithDeadline(ctx context.Context, p Person) (string, error) {
+	res, err := c.greet.GreetWithDeadline(ctx, &greetpb.GreetWithDeadlineRequest{Greeting: p.greeting()})
+	if err != nil {
+		return "", err
+	}
+	return res.GetResult(), nil
+}
+
+// GreetingIterator iterates over the greetings a call streams back:
+//
+//	it := c.GreetManyTimes(ctx, p)
+//	defer it.Close()
+//	for it.Next() {
+//		fmt.Println(it.Value())
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+type GreetingIterator struct {
+	stream greetpb.GreetService_GreetManyTimesClient
+	cancel context.CancelFunc
+	value  string
+	err    error
+}
+
+// Next advances to the next greeting, returning false at the end of the
+// stream or on error.
+func (it *GreetingIterator) Next() bool {
+	if it.stream == nil {
+		return false
+	}
+	res, err := it.stream.Recv()
+	if err != nil {
+		if err != io.EOF {
+			it.err = err
+		}
+		it.Close()
+		return false
+	}
+	it.value = res.GetResult()
+	return true
+}
+
+// Value returns the current greeting.
+func (it *GreetingIterator) Value() string { return it.value }
+
+// Err returns the error that ended the stream, if any.
+func (it *GreetingIterator) Err() error { return it.err }
+
+// Close cancels the call if it is still running.
+func (it *GreetingIterator) Close() {
+	it.stream = nil
+	it.cancel()
+}
+
+// GreetManyTimes streams several greetings for p.
+func (c *Client) GreetManyTimes(ctx context.Context, p Person) *GreetingIterator {
+	ctx, cancel := context.WithCancel(ctx)
+	it := &GreetingIterator{cancel: cancel}
+	stream, err := c.greet.GreetManyTimes(ctx, &greetpb.GreetManyTimesRequest{Greeting: p.greeting()})
+	if err != nil {
+		it.err = err
+		cancel()
+		return it
+	}
+	it.stream = stream
+	return it
+}
+
+// LongGreet streams people to the server and returns its single greeting
+// for all of them.
+func (c *Client) LongGreet(ctx context.Context, people []Person) (string, error) {
+	stream, err := c.greet.LongGreet(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, p := range people {
+		if err := stream.Send(&greetpb.LongGreetRequest{Greeting: p.greeting()}); err != nil {
+			if err == io.EOF {
+				// The server ended the call; its status comes with the
+				// response.
+				break
+			}
+			return "", err
+		}
+	}
+	res, err := stream.CloseAndRecv()
+	if err != nil {
+		return "", err
+	}
+	return res.GetResult(), nil
+}
+
+// GreetEveryone streams people to the server and returns the greetings it
+// streams back, in order.
+func (c *Client) GreetEveryone(ctx context.Context, people []Person) ([]string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := c.greet.GreetEveryone(ctx)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		for _, p := range people {
+			if err := stream.Send(&greetpb.GreetEveryoneRequest{Greeting: p.greeting()}); err != nil {
+				return
+			}
+		}
+		stream.CloseSend()
+	}()
+	var greetings []string
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			return greetings, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		greetings = append(greetings, res.GetResult())
+	}
+}
+
+// GreetingStats returns how often p has been greeted, or everyone greeted
+// so far if p is nil, together with the total number of greetings. It fails
+// with NOT_FOUND if p has never been greeted.
+func (c *Client) GreetingStats(ctx context.Context, p *Person) ([]Stats, int64, error) {
+	req := &greetpb.GetGreetingStatsRequest{}
+	if p != nil {
+		req.Greeting = p.greeting()
+	}
+	res, err := c.greet.GetGreetingStats(ctx, req)
+	if err != nil {
+		return nil, 0, err
+	}
+	stats := make([]Stats, 0, len(res.GetStats()))
+	for _, s := range res.GetStats() {
+		stats = append(stats, Stats{
+			Person:    Person{FirstName: s.GetGreeting().GetFirstName(), LastName: s.GetGreeting().GetLastName()},
+			Visits:    s.GetVisits(),
+			FirstSeen: s.GetFirstSeen().AsTime(),
+			LastSeen:  s.GetLastSeen().AsTime(),
+		})
+	}
+	return stats, res.GetTotalVisits(), nil
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// defaultDeadline is applied to unary calls whose client did not set one.
+	defaultDeadline = 10 * time.Second
+
+	// downstreamMargin is kept back from the remaining budget when a handler
+	// calls further down, so it still has time to answer its own caller.
+	downstreamMargin = 100 * time.Millisecond
+)
+
+// downstreamContext derives the context for a downstream call from the
+// incoming one, passing on what is left of the deadline minus a margin.
+func downstreamContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return context.WithCancel(ctx)
+	}
+	return context.WithDeadline(ctx, deadline.Add(-downstreamMargin))
+}
+
+// contextError converts the error of a finished context into the matching
+// gRPC status. It returns nil while the context is still live.
+func contextError(ctx context.Context) error {
+	switch ctx.Err() {
+	case context.Canceled:
+		log.Println("The client canceled the request")
+		return status.Error(codes.Canceled, "client canceled the request")
+	case context.DeadlineExceeded:
+		log.Println("The deadline was exceeded")
+		return status.Error(codes.DeadlineExceeded, "deadline exceeded")
+	}
+	return nil
+}
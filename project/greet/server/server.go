@@ -4,70 +4,150 @@ import (
 	"context"
 	"fmt"
 	"github.com/grpc-project02/project/greet/greetpb"
+	"github.com/grpc-project02/project/greet/visits"
+	"github.com/grpc-project02/project/internal/serverconfig"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/reflection"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 	"io"
 	"log"
 	"net"
+	"os"
+	"strings"
 	"time"
 )
 
 type server struct {
+	visits visits.Store
+}
+
+// welcome records a visit by the greeted person and words the greeting by how
+// often they have been here.
+func (s *server) welcome(greeting *greetpb.Greeting) (string, error) {
+	firstName := greeting.GetFirstName()
+	lastName := greeting.GetLastName()
+	visit, err := s.visits.Record(firstName, lastName, time.Now())
+	if err != nil {
+		log.Printf("Error while recording visit: %v\n", err)
+		return "", status.Errorf(codes.Internal, "recording visit: %v", err)
+	}
+	if visit.Count == 1 {
+		return strings.TrimSpace(fmt.Sprintf("Hello %s %s", firstName, lastName)), nil
+	}
+	return fmt.Sprintf("Welcome back, %s — visit #%d", firstName, visit.Count), nil
+}
+
+func (s *server) GetGreetingStats(ctx context.Context, request *greetpb.GetGreetingStatsRequest) (*greetpb.GetGreetingStatsResponse, error) {
+	log.Printf("GetGreetingStats function was invoked with %v\n", request)
+
+	var list []visits.Visit
+	if greeting := request.GetGreeting(); greeting != nil {
+		if err := invalidArgument(validateGreeting("greeting", greeting)); err != nil {
+			return nil, err
+		}
+		visit, ok, err := s.visits.Get(greeting.GetFirstName(), greeting.GetLastName())
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "reading visits: %v", err)
+		}
+		if !ok {
+			return nil, status.Errorf(codes.NotFound, "%s %s has not been greeted yet", greeting.GetFirstName(), greeting.GetLastName())
+		}
+		list = append(list, visit)
+	} else {
+		var err error
+		if list, err = s.visits.List(); err != nil {
+			return nil, status.Errorf(codes.Internal, "reading visits: %v", err)
+		}
+	}
+
+	res := &greetpb.GetGreetingStatsResponse{}
+	for _, visit := range list {
+		res.Stats = append(res.Stats, &greetpb.GreetingStats{
+			Greeting: &greetpb.Greeting{
+				FirstName: visit.FirstName,
+				LastName:  visit.LastName,
+			},
+			Visits:    visit.Count,
+			FirstSeen: timestamppb.New(visit.FirstSeen),
+			LastSeen:  timestamppb.New(visit.LastSeen),
+		})
+		res.TotalVisits += visit.Count
+	}
+	return res, nil
 }
 
 func (s *server) GreetWithDeadline(ctx context.Context, request *greetpb.GreetWithDeadlineRequest) (*greetpb.GreetWithDeadlineResponse, error) {
 	log.Printf("GreetWithDeadline function was invoked with %v\n", request)
+	if err := invalidArgument(validateGreeting("greeting", request.GetGreeting())); err != nil {
+		return nil, err
+	}
 
-	for i := 0; i < 3; i++ {
-		if ctx.Err() == context.Canceled {
-			log.Println("The client canceled the request")
-			return nil, status.Errorf(codes.Canceled, "client canceled the request")
-		}
-		time.Sleep(1 * time.Second)
+	downstreamCtx, cancel := downstreamContext(ctx)
+	defer cancel()
+	result, err := s.slowGreeting(downstreamCtx, request.GetGreeting())
+	if err != nil {
+		return nil, err
 	}
 
-	greeting := request.GetGreeting()
-	firstName := greeting.GetFirstName()
-	lastName := greeting.GetLastName()
-	result := fmt.Sprintf("Hello %s %s", firstName, lastName)
 	res := &greetpb.GreetWithDeadlineResponse{
 		Result: result,
 	}
 	return res, nil
 }
 
+// slowGreeting stands in for a slow downstream dependency: it takes three
+// seconds to build a greeting and gives up as soon as ctx is done.
+func (s *server) slowGreeting(ctx context.Context, greeting *greetpb.Greeting) (string, error) {
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+	for i := 0; i < 3; i++ {
+		select {
+		case <-ctx.Done():
+			return "", contextError(ctx)
+		case <-ticker.C:
+		}
+	}
+
+	return s.welcome(greeting)
+}
+
 func (s *server) GreetEveryone(everyoneServer greetpb.GreetService_GreetEveryoneServer) error {
 	log.Println("GreetEveryone function was invoked with a streaming request")
 
-	for {
+	for i := 0; ; i++ {
 		request, err := everyoneServer.Recv()
 		if err == io.EOF {
 			return nil
 		}
 		if err != nil {
-			log.Fatalf("Error while reading client stream: %v\n", err)
+			log.Printf("Error while reading client stream: %v\n", err)
+			return err
+		}
+		if err := invalidArgument(validateGreeting(streamField(i), request.GetGreeting())); err != nil {
 			return err
 		}
 
-		firstName := request.GetGreeting().GetFirstName()
-		result := "Hello " + firstName + "! "
+		result, err := s.welcome(request.GetGreeting())
+		if err != nil {
+			return err
+		}
 		err = everyoneServer.Send(&greetpb.GreetEveryoneResponse{
-			Result: result,
+			Result: result + "! ",
 		})
 		if err != nil {
-			log.Fatalf("Error while sending client stream: %v\n", err)
+			log.Printf("Error while sending client stream: %v\n", err)
 			return err
 		}
 	}
 }
 
-func (*server) LongGreet(greetServer greetpb.GreetService_LongGreetServer) error {
+func (s *server) LongGreet(greetServer greetpb.GreetService_LongGreetServer) error {
 
 	log.Println("LongGreet function was invoked with a streaming request")
 	result := "Hello"
-	for {
+	for i := 0; ; i++ {
 		req, err := greetServer.Recv()
 		if err == io.EOF {
 			return greetServer.SendAndClose(&greetpb.LongGreetResponse{
@@ -75,38 +155,53 @@ func (*server) LongGreet(greetServer greetpb.GreetService_LongGreetServer) error
 			})
 		}
 		if err != nil {
-			log.Fatalf("Error while reading client stream: %v\n", err)
+			log.Printf("Error while reading client stream: %v\n", err)
+			return err
+		}
+		if err := invalidArgument(validateGreeting(streamField(i), req.GetGreeting())); err != nil {
 			return err
 		}
 
+		if _, err := s.welcome(req.GetGreeting()); err != nil {
+			return err
+		}
 		firstName := req.GetGreeting().GetFirstName()
 		result += firstName + "! "
 	}
 }
 
-func (*server) GreetManyTimes(request *greetpb.GreetManyTimesRequest, timesServer greetpb.GreetService_GreetManyTimesServer) error {
+func (s *server) GreetManyTimes(request *greetpb.GreetManyTimesRequest, timesServer greetpb.GreetService_GreetManyTimesServer) error {
 	log.Printf("Greet Many times function was invoked with %v", request)
-	greeting := request.GetGreeting()
+	if err := invalidArgument(validateGreeting("greeting", request.GetGreeting())); err != nil {
+		return err
+	}
+	welcome, err := s.welcome(request.GetGreeting())
+	if err != nil {
+		return err
+	}
 	for i := 0; i < 10; i++ {
-		result := fmt.Sprintf("Hello %s number %d", greeting.GetFirstName(), i)
+		result := fmt.Sprintf("%s number %d", welcome, i)
 		response := &greetpb.GreetManyTimesResponse{
 			Result: result,
 		}
 		err := timesServer.Send(response)
 		if err != nil {
-			log.Fatalf("While sending response, error occurred %s", err)
+			log.Printf("While sending response, error occurred %s", err)
 			return err
 		}
 	}
 	return nil
 }
 
-func (*server) Greet(ctx context.Context, req *greetpb.GreetRequest) (*greetpb.GreetResponse, error) {
+func (s *server) Greet(ctx context.Context, req *greetpb.GreetRequest) (*greetpb.GreetResponse, error) {
 	log.Printf("Greet function was invoked with %v\n", req)
-	greeting := req.GetGreeting()
-	firstName := greeting.GetFirstName()
-	lastName := greeting.GetLastName()
-	result := fmt.Sprintf("Hello %s %s", firstName, lastName)
+	if err := invalidArgument(validateGreeting("greeting", req.GetGreeting())); err != nil {
+		return nil, err
+	}
+	result, err := s.welcome(req.GetGreeting())
+	if err != nil {
+		return nil, err
+	}
 	res := &greetpb.GreetResponse{
 		Result: result,
 	}
@@ -116,24 +211,53 @@ func (*server) Greet(ctx context.Context, req *greetpb.GreetRequest) (*greetpb.G
 func main() {
 	log.Println("Hello World")
 
-	lis, err := net.Listen("tcp", "0.0.0.0:50051")
-	defer lis.Close()
+	cfg, err := serverconfig.Load("greet", serverconfig.Config{
+		ListenAddr:      "0.0.0.0:50051",
+		TLS:             serverconfig.TLSConfig{Enabled: true, CertFile: "ssl/server.crt", KeyFile: "ssl/server.pem"},
+		Services:        []string{"greet"},
+		MaxRecvMsgSize:  4 << 20,
+		MaxSendMsgSize:  4 << 20,
+		DefaultDeadline: serverconfig.Duration(defaultDeadline),
+		Keepalive: serverconfig.KeepaliveConfig{
+			Time:    serverconfig.Duration(2 * time.Hour),
+			Timeout: serverconfig.Duration(20 * time.Second),
+			MinTime: serverconfig.Duration(5 * time.Minute),
+		},
+	}, []string{"greet", "reflection"}, os.Args[1:])
 	if err != nil {
-		log.Fatalf("Failed to listen: %v", err)
+		log.Fatalf("Failed to load configuration: %v", err)
 	}
+	log.Printf("Effective configuration:\n%v", cfg)
 
-	certFile := "ssl/server.crt"
-	keyFile := "ssl/server.pem"
-	creds, err := credentials.NewServerTLSFromFile(certFile, keyFile)
+	opts, err := cfg.ServerOptions()
 	if err != nil {
-		log.Fatalf("Failed loading certificates: %v", err)
-		return
+		log.Fatalf("Failed to build server options: %v", err)
 	}
 
-	opts := grpc.Creds(creds)
+	var store visits.Store = visits.NewMemoryStore()
+	path, err := cfg.DataPath("visits.json")
+	if err != nil {
+		log.Fatalf("Failed to prepare data directory: %v", err)
+	}
+	if path != "" {
+		if store, err = visits.NewFileStore(path); err != nil {
+			log.Fatalf("Failed to load visits: %v", err)
+		}
+	}
 
-	s := grpc.NewServer(opts)
-	greetpb.RegisterGreetServiceServer(s, &server{})
+	s := grpc.NewServer(opts...)
+	if cfg.Enabled("greet") {
+		greetpb.RegisterGreetServiceServer(s, &server{visits: store})
+	}
+	if cfg.Enabled("reflection") {
+		reflection.Register(s)
+	}
+
+	lis, err := net.Listen("tcp", cfg.ListenAddr)
+	if err != nil {
+		log.Fatalf("Failed to listen: %v", err)
+	}
+	defer lis.Close()
 
 	if err := s.Serve(lis); err != nil {
 		log.Fatalf("failed to serve: %v\n", err)
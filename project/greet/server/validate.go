@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/grpc-project02/project/greet/greetpb"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxNameLength is the longest first or last name accepted, in characters.
+const maxNameLength = 50
+
+// validateGreeting checks a greeting and returns one violation per problem.
+// field is the path of the greeting within the request, e.g. "greeting" or
+// "messages[3].greeting" for the fourth message of a stream.
+func validateGreeting(field string, greeting *greetpb.Greeting) []*errdetails.BadRequest_FieldViolation {
+	if greeting == nil {
+		return []*errdetails.BadRequest_FieldViolation{{
+			Field:       field,
+			Description: "greeting is required",
+		}}
+	}
+
+	var violations []*errdetails.BadRequest_FieldViolation
+	if strings.TrimSpace(greeting.GetFirstName()) == "" {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       field + ".first_name",
+			Description: "first name is required",
+		})
+	} else {
+		violations = append(violations, validateName(field+".first_name", greeting.GetFirstName())...)
+	}
+	if greeting.GetLastName() != "" {
+		violations = append(violations, validateName(field+".last_name", greeting.GetLastName())...)
+	}
+	return violations
+}
+
+func validateName(field, name string) []*errdetails.BadRequest_FieldViolation {
+	var violations []*errdetails.BadRequest_FieldViolation
+	if n := utf8.RuneCountInString(name); n > maxNameLength {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       field,
+			Description: fmt.Sprintf("must be at most %d characters, got %d", maxNameLength, n),
+		})
+	}
+	if !utf8.ValidString(name) {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       field,
+			Description: "must be valid UTF-8",
+		})
+	} else if i := strings.IndexFunc(name, func(r rune) bool { return !allowedNameRune(r) }); i >= 0 {
+		r, _ := utf8.DecodeRuneInString(name[i:])
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       field,
+			Description: fmt.Sprintf("contains disallowed character %q; only letters, spaces, hyphens, apostrophes and periods are allowed", r),
+		})
+	}
+	return violations
+}
+
+// allowedNameRune accepts letters in any script plus the punctuation found in
+// ordinary names.
+func allowedNameRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.Is(unicode.Mn, r) || r == ' ' || r == '-' || r == '\'' || r == '.'
+}
+
+// streamField names the greeting of the index-th message of a stream.
+func streamField(index int) string {
+	return fmt.Sprintf("messages[%d].greeting", index)
+}
+
+// invalidArgument builds an InvalidArgument status carrying the violations as
+// a BadRequest detail. It returns nil when there are none.
+func invalidArgument(violations []*errdetails.BadRequest_FieldViolation) error {
+	if len(violations) == 0 {
+		return nil
+	}
+	st := status.New(codes.InvalidArgument, fmt.Sprintf("invalid greeting: %s: %s", violations[0].GetField(), violations[0].GetDescription()))
+	detailed, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
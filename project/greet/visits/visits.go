@@ -0,0 +1,254 @@
+// Package visits remembers who the greet server has greeted, how often and
+// when.
+package visits
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Visit is what a Store knows about one person.
+type Visit struct {
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	Count     int64     `json:"count"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// Store records visits. Implementations must be safe for concurrent use.
+type Store interface {
+	// Record counts a visit by the person at the given time and returns
+	// their updated record.
+	Record(firstName, lastName string, at time.Time) (Visit, error)
+	// Get returns the record of the person and whether one exists.
+	Get(firstName, lastName string) (Visit, bool, error)
+	// List returns every record, ordered by name.
+	List() ([]Visit, error)
+}
+
+// Key identifies a person regardless of case and surrounding whitespace.
+func Key(firstName, lastName string) string {
+	return strings.ToLower(strings.Join(strings.Fields(firstName+" "+lastName), " "))
+}
+
+// MemoryStore keeps visits in memory; they are lost when the server stops.
+type MemoryStore struct {
+	mu     sync.Mutex
+	visits map[string]Visit
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{visits: make(map[string]Visit)}
+}
+
+func (m *MemoryStore) Record(firstName, lastName string, at time.Time) (Visit, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.record(firstName, lastName, at), nil
+}
+
+func (m *MemoryStore) record(firstName, lastName string, at time.Time) Visit {
+	v := m.next(firstName, lastName, at)
+	m.visits[Key(firstName, lastName)] = v
+	return v
+}
+
+// next returns the record of the person after a visit at the given time,
+// without storing it. m.mu must be held.
+func (m *MemoryStore) next(firstName, lastName string, at time.Time) Visit {
+	v, ok := m.visits[Key(firstName, lastName)]
+	if !ok {
+		v = Visit{FirstName: firstName, LastName: lastName, FirstSeen: at}
+	}
+	v.Count++
+	v.LastSeen = at
+	return v
+}
+
+func (m *MemoryStore) Get(firstName, lastName string) (Visit, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	v, ok := m.visits[Key(firstName, lastName)]
+	return v, ok, nil
+}
+
+func (m *MemoryStore) List() ([]Visit, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	list := make([]Visit, 0, len(m.visits))
+	for _, v := range m.visits {
+		list = append(list, v)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return Key(list[i].FirstName, list[i].LastName) < Key(list[j].FirstName, list[j].LastName)
+	})
+	return list, nil
+}
+
+// compactSlack is how many lines beyond twice the number of people the file
+// of a FileStore may grow to before it is rewritten.
+const compactSlack = 1000
+
+// FileStore is a MemoryStore that appends every updated record to a file of
+// JSON lines, so visits survive restarts. The latest line for a person wins.
+// The file is rewritten with one line per person when the store is opened
+// and whenever it has grown to twice that, plus some slack.
+type FileStore struct {
+	*MemoryStore
+	path    string
+	file    *os.File
+	size    int64
+	written int
+}
+
+// NewFileStore loads the visits saved at path. A missing file starts empty.
+// Files holding a single JSON array, as written by earlier versions, are
+// read too and rewritten as JSON lines.
+func NewFileStore(path string) (*FileStore, error) {
+	f := &FileStore{MemoryStore: NewMemoryStore(), path: path}
+	b, err := ioutil.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	if trimmed := bytes.TrimSpace(b); bytes.HasPrefix(trimmed, []byte("[")) {
+		var list []Visit
+		if err := json.Unmarshal(trimmed, &list); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", path, err)
+		}
+		for _, v := range list {
+			f.visits[Key(v.FirstName, v.LastName)] = v
+		}
+	} else {
+		lines := bytes.Split(b, []byte("\n"))
+		for i, line := range lines {
+			if len(bytes.TrimSpace(line)) == 0 {
+				continue
+			}
+			var v Visit
+			if err := json.Unmarshal(line, &v); err != nil {
+				if i == len(lines)-1 {
+					// A write cut short by a crash; the visit was never
+					// acknowledged.
+					break
+				}
+				return nil, fmt.Errorf("parsing %s:%d: %w", path, i+1, err)
+			}
+			f.visits[Key(v.FirstName, v.LastName)] = v
+		}
+	}
+
+	if err := f.compact(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Record appends the updated record to the file and only then keeps it in
+// memory, so a failed write leaves the store as it was.
+func (f *FileStore) Record(firstName, lastName string, at time.Time) (Visit, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.written >= 2*len(f.visits)+compactSlack {
+		if err := f.compact(); err != nil {
+			return Visit{}, err
+		}
+	}
+
+	v := f.next(firstName, lastName, at)
+	b, err := json.Marshal(v)
+	if err != nil {
+		return Visit{}, err
+	}
+	b = append(b, '\n')
+	if _, err := f.file.Write(b); err != nil {
+		// Drop whatever part of the line was written, so that the next one
+		// starts on a line of its own.
+		f.file.Truncate(f.size)
+		return Visit{}, err
+	}
+	f.size += int64(len(b))
+	f.written++
+	f.visits[Key(firstName, lastName)] = v
+	return v, nil
+}
+
+// Close closes the underlying file.
+func (f *FileStore) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Close()
+}
+
+// compact writes one line per person to a temporary file, renames it over
+// the old one and reopens it for appending, so a crash never leaves a
+// half-written file behind. f.mu must be held, or f not yet shared.
+func (f *FileStore) compact() error {
+	list := make([]Visit, 0, len(f.visits))
+	for _, v := range f.visits {
+		list = append(list, v)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return Key(list[i].FirstName, list[i].LastName) < Key(list[j].FirstName, list[j].LastName)
+	})
+
+	// TempFile creates files only their owner can read; keep the mode of
+	// the file replaced, or 0644 for a new one.
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(f.path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(f.path), filepath.Base(f.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(w)
+	for _, v := range list {
+		if err = enc.Encode(v); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = tmp.Chmod(mode)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), f.path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if f.file != nil {
+		f.file.Close()
+	}
+	if f.file, err = os.OpenFile(f.path, os.O_WRONLY|os.O_APPEND, 0644); err != nil {
+		return err
+	}
+	info, err := f.file.Stat()
+	if err != nil {
+		return err
+	}
+	f.size = info.Size()
+	f.written = len(list)
+	return nil
+}
@@ -0,0 +1,56 @@
+package visits
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestFileStoreMode checks that compaction, which replaces the file, keeps
+// its permissions.
+func TestFileStoreMode(t *testing.T) {
+	dir, err := ioutil.TempDir("", "visits")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name    string
+		existed bool
+		mode    os.FileMode
+	}{
+		{"new", false, 0644},
+		{"private", true, 0600},
+		{"group readable", true, 0640},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(dir, tt.name+".json")
+		if tt.existed {
+			if err := ioutil.WriteFile(path, nil, tt.mode); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Chmod(path, tt.mode); err != nil {
+				t.Fatal(err)
+			}
+		}
+		f, err := NewFileStore(path)
+		if err != nil {
+			t.Fatalf("%s: NewFileStore: %v", tt.name, err)
+		}
+		if _, err := f.Record("Ada", "Lovelace", time.Now()); err != nil {
+			t.Fatalf("%s: Record: %v", tt.name, err)
+		}
+		f.Close()
+
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := info.Mode().Perm(); got != tt.mode {
+			t.Errorf("%s: mode after compaction is %v, want %v", tt.name, got, tt.mode)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+// Package clientconn dials the greet and calculator servers for their client
+// libraries. Connections use TLS unless told otherwise, bound each unary
+// call by a default timeout and retry calls that fail with UNAVAILABLE.
+//
+// Retries are expressed as a gRPC service config, so they apply to
+// streaming calls too: a stream is retried only until the server has sent
+// its first message. Streams are not given a timeout, as many of them run
+// for as long as the client wants.
+package clientconn
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+const (
+	// DefaultTimeout bounds unary calls whose context has no deadline.
+	DefaultTimeout = 30 * time.Second
+
+	// DefaultAttempts is how many times a call is tried in all.
+	DefaultAttempts = 3
+
+	// DefaultBackoff is the wait before the first retry, doubled for each
+	// later one.
+	DefaultBackoff = 100 * time.Millisecond
+
+	// maxAttempts is the most attempts gRPC makes, whatever is configured.
+	maxAttempts = 5
+)
+
+type options struct {
+	// creds builds the transport credentials; the last TLS option wins.
+	creds       func() (credentials.TransportCredentials, error)
+	timeout     time.Duration
+	attempts    int
+	backoff     time.Duration
+	dialOptions []grpc.DialOption
+}
+
+// Option configures Dial.
+type Option func(*options)
+
+// WithInsecure connects without TLS.
+func WithInsecure() Option {
+	return func(o *options) {
+		o.creds = func() (credentials.TransportCredentials, error) { return insecure.NewCredentials(), nil }
+	}
+}
+
+// WithCAFile verifies the server against the CA certificate in the PEM file
+// at path instead of the system roots.
+func WithCAFile(path string) Option {
+	return func(o *options) {
+		o.creds = func() (credentials.TransportCredentials, error) {
+			creds, err := credentials.NewClientTLSFromFile(path, "")
+			if err != nil {
+				return nil, fmt.Errorf("loading CA certificate: %w", err)
+			}
+			return creds, nil
+		}
+	}
+}
+
+// WithTLSConfig connects with the given TLS configuration.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(o *options) {
+		o.creds = func() (credentials.TransportCredentials, error) { return credentials.NewTLS(config), nil }
+	}
+}
+
+// WithTimeout bounds each unary call by d unless its context already has a
+// deadline. Zero leaves calls unbounded.
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) { o.timeout = d }
+}
+
+// WithRetries tries each idempotent call up to attempts times in all, at
+// most 5, while it fails with UNAVAILABLE, waiting backoff before the first retry and
+// twice as long before each later one. One attempt disables retries.
+func WithRetries(attempts int, backoff time.Duration) Option {
+	return func(o *options) { o.attempts, o.backoff = attempts, backoff }
+}
+
+// WithDialOptions passes extra options to grpc.Dial, after the ones built
+// from the other options.
+func WithDialOptions(opts ...grpc.DialOption) Option {
+	return func(o *options) { o.dialOptions = append(o.dialOptions, opts...) }
+}
+
+// methodConfig is an entry of the methodConfig list of a gRPC service config.
+type methodConfig struct {
+	Name        []map[string]string `json:"name"`
+	RetryPolicy *retryPolicy        `json:"retryPolicy,omitempty"`
+}
+
+type retryPolicy struct {
+	MaxAttempts          int      `json:"maxAttempts"`
+	InitialBackoff       string   `json:"initialBackoff"`
+	MaxBackoff           string   `json:"maxBackoff"`
+	BackoffMultiplier    float64  `json:"backoffMultiplier"`
+	RetryableStatusCodes []string `json:"retryableStatusCodes"`
+}
+
+// seconds formats d as a service config duration.
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.9fs", d.Seconds())
+}
+
+// serviceConfig builds the service config applying o to the given services,
+// except that the methods in once, named "service/method", are not retried.
+func (o *options) serviceConfig(services, once []string) (string, error) {
+	mc := methodConfig{}
+	for _, s := range services {
+		mc.Name = append(mc.Name, map[string]string{"service": s})
+	}
+	configs := []methodConfig{mc}
+	if o.attempts > 1 {
+		backoff := o.backoff
+		if backoff <= 0 {
+			backoff = DefaultBackoff
+		}
+		attempts := o.attempts
+		if attempts > maxAttempts {
+			attempts = maxAttempts
+		}
+		configs[0].RetryPolicy = &retryPolicy{
+			MaxAttempts:          attempts,
+			InitialBackoff:       seconds(backoff),
+			MaxBackoff:           seconds(backoff << (attempts - 2)),
+			BackoffMultiplier:    2,
+			RetryableStatusCodes: []string{"UNAVAILABLE"},
+		}
+		// A method's own entry takes precedence over its service's.
+		if len(once) > 0 {
+			single := methodConfig{}
+			for _, m := range once {
+				i := strings.LastIndex(m, "/")
+				if i < 0 {
+					return "", fmt.Errorf("method %q is not of the form service/method", m)
+				}
+				single.Name = append(single.Name, map[string]string{"service": m[:i], "method": m[i+1:]})
+			}
+			configs = append(configs, single)
+		}
+	}
+	b, err := json.Marshal(map[string][]methodConfig{"methodConfig": configs})
+	return string(b), err
+}
+
+// timeoutInterceptor gives unary calls without a deadline the timeout d.
+func timeoutInterceptor(d time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if _, ok := ctx.Deadline(); !ok {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, d)
+			defer cancel()
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// Dial connects to target for the given fully qualified service names, such
+// as "calculator.CalculatorService". The methods in once, such as
+// "calculator.OperationsService/StartOperation", are those that must not run
+// twice and so are not retried. Like grpc.Dial, it does not wait for the
+// connection to be established.
+func Dial(target string, services, once []string, opts ...Option) (*grpc.ClientConn, error) {
+	o := &options{
+		creds: func() (credentials.TransportCredentials, error) {
+			return credentials.NewTLS(&tls.Config{}), nil
+		},
+		timeout:  DefaultTimeout,
+		attempts: DefaultAttempts,
+		backoff:  DefaultBackoff,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	creds, err := o.creds()
+	if err != nil {
+		return nil, err
+	}
+	config, err := o.serviceConfig(services, once)
+	if err != nil {
+		return nil, fmt.Errorf("building service config: %w", err)
+	}
+	dialOptions := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithDefaultServiceConfig(config),
+	}
+	if o.timeout > 0 {
+		dialOptions = append(dialOptions, grpc.WithChainUnaryInterceptor(timeoutInterceptor(o.timeout)))
+	}
+	return grpc.Dial(target, append(dialOptions, o.dialOptions...)...)
+}
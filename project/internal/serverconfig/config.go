@@ -0,0 +1,348 @@
+// Package serverconfig loads the bootstrap settings shared by the greet and
+// calculator servers. Values are resolved in increasing order of precedence:
+// built-in defaults, a JSON config file, environment variables and finally
+// command-line flags.
+package serverconfig
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
+)
+
+// Config is the effective configuration of a server.
+type Config struct {
+	ListenAddr      string          `json:"listen_addr"`
+	TLS             TLSConfig       `json:"tls"`
+	Services        []string        `json:"services"`
+	MaxRecvMsgSize  int             `json:"max_recv_msg_size"`
+	MaxSendMsgSize  int             `json:"max_send_msg_size"`
+	DefaultDeadline Duration        `json:"default_deadline"`
+	Keepalive       KeepaliveConfig `json:"keepalive"`
+	DataDir         string          `json:"data_dir"`
+	// Auditors are the client addresses allowed to read what other clients
+	// did, such as every caller's calculator history.
+	Auditors []string `json:"auditors"`
+}
+
+// TLSConfig holds the server certificate and key.
+type TLSConfig struct {
+	Enabled  bool   `json:"enabled"`
+	CertFile string `json:"cert_file"`
+	KeyFile  string `json:"key_file"`
+}
+
+// KeepaliveConfig maps onto keepalive.ServerParameters and
+// keepalive.EnforcementPolicy.
+type KeepaliveConfig struct {
+	Time                Duration `json:"time"`
+	Timeout             Duration `json:"timeout"`
+	MaxConnectionIdle   Duration `json:"max_connection_idle"`
+	MinTime             Duration `json:"min_time"`
+	PermitWithoutStream bool     `json:"permit_without_stream"`
+}
+
+// Duration is a time.Duration written as "5s" or "1m30s" in config files.
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Load builds the configuration for the server called name. defaults holds
+// the built-in values, known lists the services the server can register and
+// args are the command-line arguments without the program name. Environment
+// variables are upper-cased flag names prefixed with the server name, e.g.
+// GREET_LISTEN_ADDR.
+func Load(name string, defaults Config, known []string, args []string) (*Config, error) {
+	cfg := defaults
+	cfg.Services = append([]string(nil), defaults.Services...)
+	cfg.Auditors = append([]string(nil), defaults.Auditors...)
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	configFile := fs.String("config", "", "path to a JSON config file")
+	fs.String("listen_addr", cfg.ListenAddr, "address to listen on")
+	fs.Bool("tls", cfg.TLS.Enabled, "serve over TLS")
+	fs.String("tls_cert", cfg.TLS.CertFile, "TLS certificate file")
+	fs.String("tls_key", cfg.TLS.KeyFile, "TLS private key file")
+	fs.String("services", strings.Join(cfg.Services, ","), "comma-separated services to enable ("+strings.Join(known, ", ")+")")
+	fs.Int("max_recv_msg_size", cfg.MaxRecvMsgSize, "maximum message size the server can receive, in bytes")
+	fs.Int("max_send_msg_size", cfg.MaxSendMsgSize, "maximum message size the server can send, in bytes")
+	fs.Duration("default_deadline", time.Duration(cfg.DefaultDeadline), "deadline applied to unary calls without one (0 disables)")
+	fs.Duration("keepalive_time", time.Duration(cfg.Keepalive.Time), "ping idle clients after this long")
+	fs.Duration("keepalive_timeout", time.Duration(cfg.Keepalive.Timeout), "close the connection if a ping is not acknowledged in time")
+	fs.Duration("keepalive_max_idle", time.Duration(cfg.Keepalive.MaxConnectionIdle), "close connections idle for this long (0 disables)")
+	fs.Duration("keepalive_min_time", time.Duration(cfg.Keepalive.MinTime), "minimum interval clients may ping at")
+	fs.Bool("keepalive_permit_without_stream", cfg.Keepalive.PermitWithoutStream, "allow client pings without active streams")
+	fs.String("data_dir", cfg.DataDir, "directory for persistent state (empty keeps state in memory)")
+	fs.String("auditors", strings.Join(cfg.Auditors, ","), "comma-separated client addresses allowed to read what other clients did")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	path := *configFile
+	if path == "" {
+		path = os.Getenv(envName(name, "config"))
+	}
+	if path != "" {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading config file: %w", err)
+		}
+		if err := json.Unmarshal(b, &cfg); err != nil {
+			return nil, fmt.Errorf("parsing config file %s: %w", path, err)
+		}
+	}
+
+	// Environment variables override the file, explicitly set flags override both.
+	var errs []string
+	fs.VisitAll(func(f *flag.Flag) {
+		if v, ok := os.LookupEnv(envName(name, f.Name)); ok && f.Name != "config" {
+			if err := cfg.set(f.Name, v); err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %v", envName(name, f.Name), err))
+			}
+		}
+	})
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name != "config" {
+			if err := cfg.set(f.Name, f.Value.String()); err != nil {
+				errs = append(errs, fmt.Sprintf("-%s: %v", f.Name, err))
+			}
+		}
+	})
+	if len(errs) > 0 {
+		return nil, errors.New(strings.Join(errs, "; "))
+	}
+
+	if err := cfg.Validate(known); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+func envName(server, flagName string) string {
+	return strings.ToUpper(server + "_" + flagName)
+}
+
+// set assigns the setting behind a flag name from its string form.
+func (c *Config) set(name, value string) error {
+	var err error
+	switch name {
+	case "listen_addr":
+		c.ListenAddr = value
+	case "tls":
+		c.TLS.Enabled, err = strconv.ParseBool(value)
+	case "tls_cert":
+		c.TLS.CertFile = value
+	case "tls_key":
+		c.TLS.KeyFile = value
+	case "services":
+		c.Services = splitList(value)
+	case "max_recv_msg_size":
+		c.MaxRecvMsgSize, err = strconv.Atoi(value)
+	case "max_send_msg_size":
+		c.MaxSendMsgSize, err = strconv.Atoi(value)
+	case "default_deadline":
+		err = setDuration(&c.DefaultDeadline, value)
+	case "keepalive_time":
+		err = setDuration(&c.Keepalive.Time, value)
+	case "keepalive_timeout":
+		err = setDuration(&c.Keepalive.Timeout, value)
+	case "keepalive_max_idle":
+		err = setDuration(&c.Keepalive.MaxConnectionIdle, value)
+	case "keepalive_min_time":
+		err = setDuration(&c.Keepalive.MinTime, value)
+	case "keepalive_permit_without_stream":
+		c.Keepalive.PermitWithoutStream, err = strconv.ParseBool(value)
+	case "data_dir":
+		c.DataDir = value
+	case "auditors":
+		c.Auditors = splitList(value)
+	default:
+		err = fmt.Errorf("unknown setting %q", name)
+	}
+	return err
+}
+
+// splitList splits a comma-separated list, dropping empty items.
+func splitList(value string) []string {
+	var list []string
+	for _, s := range strings.Split(value, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+func setDuration(d *Duration, value string) error {
+	v, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Validate reports every invalid setting at once.
+func (c *Config) Validate(known []string) error {
+	var errs []string
+	if _, _, err := net.SplitHostPort(c.ListenAddr); err != nil {
+		errs = append(errs, fmt.Sprintf("listen_addr %q: %v", c.ListenAddr, err))
+	}
+	if c.TLS.Enabled {
+		for _, f := range []struct{ name, path string }{{"cert_file", c.TLS.CertFile}, {"key_file", c.TLS.KeyFile}} {
+			if f.path == "" {
+				errs = append(errs, fmt.Sprintf("tls.%s is required when TLS is enabled", f.name))
+			} else if _, err := os.Stat(f.path); err != nil {
+				errs = append(errs, fmt.Sprintf("tls.%s: %v", f.name, err))
+			}
+		}
+	}
+	if len(c.Services) == 0 {
+		errs = append(errs, "at least one service must be enabled")
+	}
+	for _, s := range c.Services {
+		if !contains(known, s) {
+			errs = append(errs, fmt.Sprintf("unknown service %q (known: %s)", s, strings.Join(known, ", ")))
+		}
+	}
+	if c.MaxRecvMsgSize <= 0 {
+		errs = append(errs, "max_recv_msg_size must be positive")
+	}
+	if c.MaxSendMsgSize <= 0 {
+		errs = append(errs, "max_send_msg_size must be positive")
+	}
+	for _, d := range []struct {
+		name string
+		v    Duration
+	}{
+		{"default_deadline", c.DefaultDeadline},
+		{"keepalive.time", c.Keepalive.Time},
+		{"keepalive.timeout", c.Keepalive.Timeout},
+		{"keepalive.max_connection_idle", c.Keepalive.MaxConnectionIdle},
+		{"keepalive.min_time", c.Keepalive.MinTime},
+	} {
+		if d.v < 0 {
+			errs = append(errs, fmt.Sprintf("%s must not be negative", d.name))
+		}
+	}
+	if c.DataDir != "" {
+		if fi, err := os.Stat(c.DataDir); err == nil && !fi.IsDir() {
+			errs = append(errs, fmt.Sprintf("data_dir %q is not a directory", c.DataDir))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// Auditor reports whether the client with the given address may read what
+// other clients did.
+func (c *Config) Auditor(client string) bool {
+	return client != "" && contains(c.Auditors, client)
+}
+
+// Enabled reports whether the named service should be registered.
+func (c *Config) Enabled(service string) bool {
+	return contains(c.Services, service)
+}
+
+// DataPath returns the path of the named file inside the data directory,
+// creating the directory if needed. It returns "" when no data directory is
+// configured, meaning state should be kept in memory.
+func (c *Config) DataPath(name string) (string, error) {
+	if c.DataDir == "" {
+		return "", nil
+	}
+	if err := os.MkdirAll(c.DataDir, 0755); err != nil {
+		return "", err
+	}
+	return filepath.Join(c.DataDir, name), nil
+}
+
+// ServerOptions turns the configuration into grpc.NewServer options.
+func (c *Config) ServerOptions() ([]grpc.ServerOption, error) {
+	opts := []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(c.MaxRecvMsgSize),
+		grpc.MaxSendMsgSize(c.MaxSendMsgSize),
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			Time:              time.Duration(c.Keepalive.Time),
+			Timeout:           time.Duration(c.Keepalive.Timeout),
+			MaxConnectionIdle: time.Duration(c.Keepalive.MaxConnectionIdle),
+		}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             time.Duration(c.Keepalive.MinTime),
+			PermitWithoutStream: c.Keepalive.PermitWithoutStream,
+		}),
+	}
+	if c.DefaultDeadline > 0 {
+		opts = append(opts, grpc.ChainUnaryInterceptor(defaultDeadlineInterceptor(time.Duration(c.DefaultDeadline))))
+	}
+	if c.TLS.Enabled {
+		creds, err := credentials.NewServerTLSFromFile(c.TLS.CertFile, c.TLS.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading certificates: %w", err)
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+	return opts, nil
+}
+
+// defaultDeadlineInterceptor gives every unary call without a deadline the
+// server-wide default, so no handler can run unbounded.
+func defaultDeadlineInterceptor(timeout time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if _, ok := ctx.Deadline(); !ok {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		return handler(ctx, req)
+	}
+}
+
+// String renders the configuration for the startup log.
+func (c *Config) String() string {
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("%+v", *c)
+	}
+	return string(b)
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}